@@ -0,0 +1,168 @@
+package hashstructure
+
+import (
+	"encoding/binary"
+	"fmt"
+	"reflect"
+)
+
+// The canonical encoding used by FormatV2 prefixes every value with a tag
+// identifying its kind. Variable-length values (strings, slices, maps, ...)
+// additionally carry their length as a little-endian uint64 right after the
+// tag, and structs are terminated by tagEnd. Together this makes the byte
+// stream self-delimiting, so two different values can never write the same
+// bytes.
+//
+// The tag values are part of the FormatV2 output and must never change.
+const (
+	tagInvalid byte = iota
+	tagNil
+	tagBool
+	tagInt8
+	tagInt16
+	tagInt32
+	tagInt64
+	tagUint8
+	tagUint16
+	tagUint32
+	tagUint64
+	tagFloat32
+	tagFloat64
+	tagComplex64
+	tagComplex128
+	tagString
+	tagArray
+	tagSlice
+	tagSet
+	tagMap
+	tagStruct
+	tagEnd
+	tagTime
+	tagHashable
+	tagBackRef
+	tagNotNil
+	tagCustom
+	tagBinary
+	tagText
+	tagNode
+)
+
+// numberTag returns the tag for a numeric kind.
+func numberTag(k reflect.Kind) byte {
+	switch k {
+	case reflect.Int8:
+		return tagInt8
+	case reflect.Int16:
+		return tagInt16
+	case reflect.Int32:
+		return tagInt32
+	case reflect.Int64:
+		return tagInt64
+	case reflect.Uint8:
+		return tagUint8
+	case reflect.Uint16:
+		return tagUint16
+	case reflect.Uint32:
+		return tagUint32
+	case reflect.Uint64:
+		return tagUint64
+	case reflect.Float32:
+		return tagFloat32
+	case reflect.Float64:
+		return tagFloat64
+	case reflect.Complex64:
+		return tagComplex64
+	case reflect.Complex128:
+		return tagComplex128
+	}
+
+	return tagInvalid
+}
+
+// canonical reports whether the format uses the canonical encoding.
+func (f Format) canonical() bool {
+	return f >= FormatV2
+}
+
+// Write implements io.Writer. All bytes that make up the hash of a value
+// go through here.
+func (w *walker) Write(p []byte) (int, error) {
+	if w.limits != nil {
+		if err := w.checkBytes(len(p)); err != nil {
+			return 0, err
+		}
+	}
+
+	if w.tree {
+		for _, n := range w.nodes {
+			n.Bytes = append(n.Bytes, p...)
+		}
+	}
+
+	return w.h.Write(p)
+}
+
+// writeTag writes the tag of a fixed-size value. It does nothing for
+// formats that predate the canonical encoding.
+func (w *walker) writeTag(tag byte) error {
+	if !w.format.canonical() {
+		return nil
+	}
+
+	_, err := w.Write([]byte{tag})
+	return err
+}
+
+// writeHeader writes the tag and length of a variable-length value. It does
+// nothing for formats that predate the canonical encoding.
+func (w *walker) writeHeader(tag byte, n int) error {
+	if !w.format.canonical() {
+		return nil
+	}
+
+	var buf [9]byte
+	buf[0] = tag
+	binary.LittleEndian.PutUint64(buf[1:], uint64(n))
+	_, err := w.Write(buf[:])
+	return err
+}
+
+// writeHashable writes the result of Hashable.Hash.
+func (w *walker) writeHashable(h []byte) error {
+	if !w.format.canonical() {
+		_, err := fmt.Fprintf(w, "%d", h)
+		return err
+	}
+
+	if err := w.writeHeader(tagHashable, len(h)); err != nil {
+		return err
+	}
+	_, err := w.Write(h)
+	return err
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// visitOptionalCanonical is the FormatV2 counterpart of visitOptional. Rather
+// than special-casing each optional type, it hashes the wrapped value exactly
+// like a plain value of that type, and an absent value as nil.
+func (w *walker) visitOptionalCanonical(v reflect.Value) error {
+	present := v.MethodByName("Present").Call(nil)[0].Bool()
+	if !present && !w.opts.ZeroNil {
+		return w.writeTag(tagNil)
+	}
+
+	orElse := v.MethodByName("OrElse")
+	inner := orElse.Call([]reflect.Value{reflect.Zero(orElse.Type().In(0))})[0]
+
+	// optional.Error is hashed by its message, like in visitOptional
+	if inner.Type() == errorType {
+		var msg string
+		if !inner.IsNil() {
+			msg = inner.Interface().(error).Error()
+		}
+		inner = reflect.ValueOf(msg)
+	}
+
+	return w.visit(inner, nil)
+}
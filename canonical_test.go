@@ -0,0 +1,145 @@
+package hashstructure
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/markphelps/optional"
+)
+
+func TestHash_canonical(t *testing.T) {
+	type Test struct {
+		A, B string
+	}
+
+	type TestSet struct {
+		Friends []string `hash:"set"`
+	}
+
+	type TestPtr struct {
+		P *int64
+	}
+	zero := int64(0)
+
+	cases := []struct {
+		One, Two interface{}
+		Match    bool
+		// LegacyMatch is the result with the formats before FormatV2
+		LegacyMatch bool
+	}{
+		{
+			Test{A: "aB", B: ""},
+			Test{A: "a", B: "B"},
+			false,
+			true,
+		},
+		{
+			int8(1),
+			true,
+			false,
+			true,
+		},
+		{
+			[]string{"ab", "c"},
+			[]string{"a", "bc"},
+			false,
+			true,
+		},
+		{
+			[]interface{}{[]int8{1}, int8(2)},
+			[]interface{}{[]int8{1, 2}},
+			false,
+			true,
+		},
+		{
+			map[string]string{"a": "bc"},
+			map[string]string{"ab": "c"},
+			false,
+			false,
+		},
+		{
+			map[string]int{"a": 1, "b": 2},
+			map[string]int{"a": 2, "b": 1},
+			false,
+			true,
+		},
+		{
+			map[string]int{"a": 1, "b": 2},
+			map[string]int{"b": 2, "a": 1},
+			true,
+			true,
+		},
+		{
+			42,
+			int64(42),
+			true,
+			true,
+		},
+		{
+			[]interface{}{nil},
+			[]interface{}{0},
+			false,
+			true,
+		},
+		{
+			TestPtr{},
+			TestPtr{P: &zero},
+			false,
+			true,
+		},
+		{
+			map[string]interface{}{"a": nil},
+			map[string]interface{}{"a": int64(0)},
+			false,
+			true,
+		},
+		{
+			TestSet{Friends: []string{"foo", "bar"}},
+			TestSet{Friends: []string{"bar", "foo"}},
+			true,
+			true,
+		},
+		{
+			TestSet{Friends: []string{"foo", "bar"}},
+			TestSet{Friends: []string{"foo", "baz"}},
+			false,
+			true,
+		},
+		{
+			optional.NewString("foo"),
+			"foo",
+			true,
+			false,
+		},
+		{
+			optional.String{},
+			optional.NewString(""),
+			false,
+			false,
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			for _, format := range []Format{FormatMD5, FormatV2} {
+				one, err := Hash(tc.One, format, nil)
+				if err != nil {
+					t.Fatalf("Failed to hash %#v: %s", tc.One, err)
+				}
+				two, err := Hash(tc.Two, format, nil)
+				if err != nil {
+					t.Fatalf("Failed to hash %#v: %s", tc.Two, err)
+				}
+
+				match := tc.Match
+				if !format.canonical() {
+					match = tc.LegacyMatch
+				}
+				if bytes.Equal(one, two) != match {
+					t.Fatalf("bad, format %d expected: %#v\n\n%#v\n\n%#v", format, match, tc.One, tc.Two)
+				}
+			}
+		})
+	}
+}
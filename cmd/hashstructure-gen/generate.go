@@ -0,0 +1,548 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"go.openly.dev/hashstructure"
+	"go.openly.dev/hashstructure/internal/tag"
+)
+
+// config holds the settings of a run of the generator.
+type config struct {
+	// Types are the names of the types to generate Hash methods for
+	Types []string
+
+	Format hashstructure.Format
+
+	// TagName is as in hashstructure.HashOptions
+	TagName string
+}
+
+// basicKinds maps the predeclared types to the Encoder method that writes
+// them, and the type the value is converted to first, if any.
+var basicKinds = map[string][2]string{
+	"bool":       {"Bool", ""},
+	"int":        {"Int64", "int64"},
+	"int8":       {"Int8", ""},
+	"int16":      {"Int16", ""},
+	"int32":      {"Int32", ""},
+	"rune":       {"Int32", ""},
+	"int64":      {"Int64", ""},
+	"uint":       {"Uint64", "uint64"},
+	"uint8":      {"Uint8", ""},
+	"byte":       {"Uint8", ""},
+	"uint16":     {"Uint16", ""},
+	"uint32":     {"Uint32", ""},
+	"uint64":     {"Uint64", ""},
+	"uintptr":    {"Uint64", "uint64"},
+	"float32":    {"Float32", ""},
+	"float64":    {"Float64", ""},
+	"complex64":  {"Complex64", ""},
+	"complex128": {"Complex128", ""},
+	"string":     {"String", ""},
+}
+
+// hookMethods are methods that make Hash treat a struct specially. Structs
+// with any of them are left to Hash.
+var hookMethods = []string{"Hash", "HashTo", "HashInclude", "HashIncludeMap"}
+
+// typeDecl is a type declared at the top level of the package.
+type typeDecl struct {
+	spec *ast.TypeSpec
+
+	// imports maps the names of the imports of the declaring file to their
+	// paths
+	imports map[string]string
+}
+
+// generator generates the code for a single package.
+type generator struct {
+	cfg     config
+	pkg     string
+	decls   map[string]*typeDecl
+	methods map[string]map[string]*ast.FuncDecl
+
+	// suffix is appended to the names of the generated methods
+	suffix string
+
+	// queue holds the struct types to write methods for, and queued those
+	// ever queued
+	queue  []string
+	queued map[string]bool
+
+	// vars counts the variables declared by the current method, to keep
+	// their names unique
+	vars int
+
+	buf bytes.Buffer
+}
+
+func newGenerator(files []*ast.File, cfg config) (*generator, error) {
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no Go files")
+	}
+
+	g := &generator{
+		cfg:     cfg,
+		pkg:     files[0].Name.Name,
+		decls:   make(map[string]*typeDecl),
+		methods: make(map[string]map[string]*ast.FuncDecl),
+		suffix:  strings.ToUpper(cfg.Format.String()),
+		queued:  make(map[string]bool),
+	}
+
+	for _, f := range files {
+		imports := make(map[string]string)
+		for _, imp := range f.Imports {
+			path, _ := strconv.Unquote(imp.Path.Value)
+			name := path[strings.LastIndex(path, "/")+1:]
+			if imp.Name != nil {
+				name = imp.Name.Name
+			}
+			imports[name] = path
+		}
+
+		for _, d := range f.Decls {
+			switch d := d.(type) {
+			case *ast.GenDecl:
+				if d.Tok != token.TYPE {
+					continue
+				}
+				for _, s := range d.Specs {
+					spec := s.(*ast.TypeSpec)
+					g.decls[spec.Name.Name] = &typeDecl{spec: spec, imports: imports}
+				}
+
+			case *ast.FuncDecl:
+				if d.Recv == nil || len(d.Recv.List) != 1 {
+					continue
+				}
+				recv := d.Recv.List[0].Type
+				if star, ok := recv.(*ast.StarExpr); ok {
+					recv = star.X
+				}
+				if ident, ok := recv.(*ast.Ident); ok {
+					if g.methods[ident.Name] == nil {
+						g.methods[ident.Name] = make(map[string]*ast.FuncDecl)
+					}
+					g.methods[ident.Name][d.Name.Name] = d
+				}
+			}
+		}
+	}
+
+	return g, nil
+}
+
+// generate returns the generated code and test for the package.
+func generate(files []*ast.File, cfg config) (code, test []byte, err error) {
+	g, err := newGenerator(files, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	g.printf("// Code generated by hashstructure-gen. DO NOT EDIT.\n\n")
+	g.printf("package %s\n\n", g.pkg)
+	g.printf("import \"go.openly.dev/hashstructure\"\n")
+
+	for _, name := range cfg.Types {
+		if _, err := g.structType(name); err != nil {
+			return nil, nil, err
+		}
+		g.root(name)
+		g.enqueue(name)
+	}
+
+	for len(g.queue) > 0 {
+		name := g.queue[0]
+		g.queue = g.queue[1:]
+		if err := g.writer(name); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	code, err = g.format()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	g.buf.Reset()
+	g.test()
+	test, err = g.format()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return code, test, nil
+}
+
+func (g *generator) printf(format string, args ...any) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+func (g *generator) format() ([]byte, error) {
+	src, err := format.Source(g.buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("generated invalid code: %s\n%s", err, g.buf.Bytes())
+	}
+	return src, nil
+}
+
+func (g *generator) optionsExpr() string {
+	if g.cfg.TagName == "" || g.cfg.TagName == "hash" {
+		return "nil"
+	}
+	return fmt.Sprintf("&hashstructure.HashOptions{TagName: %q}", g.cfg.TagName)
+}
+
+func (g *generator) formatExpr() string {
+	return "hashstructure.Format" + g.suffix
+}
+
+// root writes the exported Hash method of the named type.
+func (g *generator) root(name string) {
+	g.printf("\n// Hash%s returns the same hash as\n//\n", g.suffix)
+	g.printf("//\thashstructure.Hash(v, %s, %s)\n//\n", g.formatExpr(), g.optionsExpr())
+	g.printf("// without reflection.\n")
+	g.printf("func (v %s) Hash%s() ([]byte, error) {\n", name, g.suffix)
+	g.printf("e, err := hashstructure.NewEncoder(%s)\n", g.formatExpr())
+	g.printf("if err != nil {\nreturn nil, err\n}\n")
+	g.printf("if err := v.hashstructure%s(e); err != nil {\nreturn nil, err\n}\n", g.suffix)
+	g.printf("return e.Sum(), nil\n}\n")
+}
+
+func (g *generator) enqueue(name string) {
+	if !g.queued[name] {
+		g.queued[name] = true
+		g.queue = append(g.queue, name)
+	}
+}
+
+// structType returns the struct type of the named type, or an error if it
+// isn't a struct that the generator supports.
+func (g *generator) structType(name string) (*ast.StructType, error) {
+	d, ok := g.decls[name]
+	if !ok {
+		return nil, fmt.Errorf("type %s not found", name)
+	}
+	if d.spec.TypeParams != nil {
+		return nil, fmt.Errorf("type %s: generic types are not supported", name)
+	}
+	for _, m := range hookMethods {
+		if _, ok := g.methods[name][m]; ok {
+			return nil, fmt.Errorf("type %s: types with a %s method are not supported", name, m)
+		}
+	}
+
+	t, _, err := g.underlying(d.spec.Type, d)
+	if err != nil {
+		return nil, fmt.Errorf("type %s: %s", name, err)
+	}
+	st, ok := t.(*ast.StructType)
+	if !ok {
+		return nil, fmt.Errorf("type %s is not a struct", name)
+	}
+	return st, nil
+}
+
+// isStruct reports whether name is a local type whose underlying type is a
+// struct.
+func (g *generator) isStruct(name string) bool {
+	d, ok := g.decls[name]
+	if !ok {
+		return false
+	}
+	t, _, err := g.underlying(d.spec.Type, d)
+	if err != nil {
+		return false
+	}
+	_, ok = t.(*ast.StructType)
+	return ok
+}
+
+// underlying follows the local type declarations that t refers to, and
+// returns the type they end in and the declaration it is in.
+func (g *generator) underlying(t ast.Expr, d *typeDecl) (ast.Expr, *typeDecl, error) {
+	for i := 0; ; i++ {
+		ident, ok := t.(*ast.Ident)
+		if !ok {
+			return t, d, nil
+		}
+		if _, ok := basicKinds[ident.Name]; ok {
+			return t, d, nil
+		}
+		next, ok := g.decls[ident.Name]
+		if !ok || i > len(g.decls) {
+			return nil, nil, fmt.Errorf("unsupported type %s", ident.Name)
+		}
+		t, d = next.spec.Type, next
+	}
+}
+
+// tag returns the value of the tag of field, and the tag it parses to.
+// Invalid values are ignored, as by Hash.
+func (g *generator) tag(field *ast.Field) (string, tag.Tag) {
+	if field.Tag == nil {
+		return "", tag.Tag{}
+	}
+
+	raw, _ := strconv.Unquote(field.Tag.Value)
+	value := reflect.StructTag(raw).Get(g.cfg.TagName)
+	t, _ := tag.Parse(value)
+	return value, t
+}
+
+// writer writes the method that writes a value of the named struct type to
+// an Encoder.
+func (g *generator) writer(name string) error {
+	st, err := g.structType(name)
+	if err != nil {
+		return err
+	}
+
+	// The fields may be declared by another type, in another file
+	_, d, _ := g.underlying(g.decls[name].spec.Type, g.decls[name])
+
+	// A blank field can set the type name, as with Hash
+	typeName := name
+	for _, field := range st.Fields.List {
+		for _, ident := range field.Names {
+			if _, tag := g.tag(field); ident.Name == "_" && tag.Name != "" && tag.NameOnly() {
+				typeName = tag.Name
+			}
+		}
+	}
+
+	g.vars = 0
+	g.printf("\nfunc (v *%s) hashstructure%s(e *hashstructure.Encoder) error {\n", name, g.suffix)
+	g.printf("e.Struct(%q)\n", typeName)
+
+	for _, field := range st.Fields.List {
+		names := field.Names
+		if names == nil {
+			// Embedded fields are named after their type
+			t := field.Type
+			if star, ok := t.(*ast.StarExpr); ok {
+				t = star.X
+			}
+			switch t := t.(type) {
+			case *ast.Ident:
+				names = []*ast.Ident{t}
+			case *ast.SelectorExpr:
+				names = []*ast.Ident{t.Sel}
+			default:
+				return fmt.Errorf("type %s: unsupported embedded field", name)
+			}
+		}
+
+		value, tag := g.tag(field)
+		if tag.Ignore {
+			continue
+		}
+		if tag.Binary || tag.Text || tag.OmitEmpty {
+			return fmt.Errorf("type %s: hash:%q is not supported", name, value)
+		}
+
+		for _, ident := range names {
+			if !ident.IsExported() {
+				continue
+			}
+
+			expr := "v." + ident.Name
+			if tag.Name != "" {
+				g.printf("e.String(%q)\n", tag.Name)
+			} else {
+				g.printf("e.String(%q)\n", ident.Name)
+			}
+
+			if tag.String {
+				if !g.isStringer(field.Type, d) {
+					return fmt.Errorf("type %s: field %s has hash:\"string\" set, but has no String method", name, ident.Name)
+				}
+				g.printf("e.String(%s.String())\n", expr)
+				continue
+			}
+
+			if err := g.value("e", expr, field.Type, d, tag.Set); err != nil {
+				return fmt.Errorf("type %s: field %s: %s", name, ident.Name, err)
+			}
+		}
+	}
+
+	g.printf("e.End()\nreturn nil\n}\n")
+	return nil
+}
+
+// isStringer reports whether values of type t have a String method.
+func (g *generator) isStringer(t ast.Expr, d *typeDecl) bool {
+	switch t := t.(type) {
+	case *ast.Ident:
+		m, ok := g.methods[t.Name]["String"]
+		if !ok {
+			return false
+		}
+		// Methods on pointers aren't in the method set of the value
+		_, ptr := m.Recv.List[0].Type.(*ast.StarExpr)
+		return !ptr
+	case *ast.SelectorExpr:
+		x, ok := t.X.(*ast.Ident)
+		return ok && d.imports[x.Name] == "time" &&
+			(t.Sel.Name == "Time" || t.Sel.Name == "Duration")
+	}
+	return false
+}
+
+// newVar returns a new variable name starting with prefix.
+func (g *generator) newVar(prefix string) string {
+	g.vars++
+	return fmt.Sprintf("%s%d", prefix, g.vars)
+}
+
+// value writes the code that writes expr, of type t declared in d, to the
+// encoder enc. set is set for fields tagged with "set".
+func (g *generator) value(enc, expr string, t ast.Expr, d *typeDecl, set bool) error {
+	// Named types keep the name of a struct, otherwise only the
+	// underlying type matters.
+	if ident, ok := t.(*ast.Ident); ok && g.isStruct(ident.Name) {
+		if _, err := g.structType(ident.Name); err != nil {
+			return err
+		}
+		g.enqueue(ident.Name)
+		g.printf("if err := %s.hashstructure%s(%s); err != nil {\nreturn err\n}\n", expr, g.suffix, enc)
+		return nil
+	}
+
+	u, ud, err := g.underlying(t, d)
+	if err != nil {
+		return err
+	}
+
+	switch u := u.(type) {
+	case *ast.Ident:
+		kind := basicKinds[u.Name]
+		if kind[1] != "" {
+			g.printf("%s.%s(%s(%s))\n", enc, kind[0], kind[1], expr)
+		} else if u != t {
+			// Convert named types to the type Encoder expects
+			g.printf("%s.%s(%s(%s))\n", enc, kind[0], u.Name, expr)
+		} else {
+			g.printf("%s.%s(%s)\n", enc, kind[0], expr)
+		}
+		return nil
+
+	case *ast.SelectorExpr:
+		x, ok := u.X.(*ast.Ident)
+		if !ok || ud.imports[x.Name] != "time" {
+			return fmt.Errorf("unsupported type %s", types(u))
+		}
+		switch u.Sel.Name {
+		case "Time":
+			if u != t {
+				// Hash treats named time types like any other struct
+				return fmt.Errorf("unsupported type %s", types(t))
+			}
+			g.printf("if err := %s.Time(%s); err != nil {\nreturn err\n}\n", enc, expr)
+			return nil
+		case "Duration":
+			g.printf("%s.Int64(int64(%s))\n", enc, expr)
+			return nil
+		}
+		return fmt.Errorf("unsupported type %s", types(u))
+
+	case *ast.StarExpr:
+		g.printf("if %s == nil {\n%s.Nil()\n} else {\n", expr, enc)
+		elem := "(*" + expr + ")"
+		if ident, ok := u.X.(*ast.Ident); ok && g.isStruct(ident.Name) {
+			// Call the method on the pointer itself
+			elem = expr
+		}
+		if err := g.value(enc, elem, u.X, ud, set); err != nil {
+			return err
+		}
+		g.printf("}\n")
+		return nil
+
+	case *ast.ArrayType:
+		if set && u.Len == nil && g.cfg.Format < hashstructure.FormatV2 {
+			g.printf("// Sets are not hashed by %s\n", g.formatExpr())
+			return nil
+		}
+
+		i := g.newVar("i")
+		elem := fmt.Sprintf("%s[%s]", expr, i)
+		switch {
+		case u.Len != nil:
+			g.printf("%s.Array(len(%s))\n", enc, expr)
+		case !set:
+			g.printf("%s.Slice(len(%s))\n", enc, expr)
+		default:
+			s := g.newVar("s")
+			g.printf("%s := %s.Set(len(%s))\n", s, enc, expr)
+			g.printf("for %s := range %s {\n", i, expr)
+			g.printf("e := %s.Elem()\n", s)
+			if err := g.value("e", elem, u.Elt, ud, false); err != nil {
+				return err
+			}
+			g.printf("}\n%s.Close()\n", s)
+			return nil
+		}
+		g.printf("for %s := range %s {\n", i, expr)
+		if err := g.value(enc, elem, u.Elt, ud, false); err != nil {
+			return err
+		}
+		g.printf("}\n")
+		return nil
+
+	case *ast.MapType:
+		m, k, x := g.newVar("m"), g.newVar("k"), g.newVar("x")
+		g.printf("%s := %s.Map(len(%s))\n", m, enc, expr)
+		g.printf("for %s, %s := range %s {\n", k, x, expr)
+		g.printf("ke, ve := %s.Entry()\n", m)
+		if err := g.value("ke", k, u.Key, ud, false); err != nil {
+			return err
+		}
+		if err := g.value("ve", x, u.Value, ud, false); err != nil {
+			return err
+		}
+		g.printf("}\n%s.Close()\n", m)
+		return nil
+	}
+
+	return fmt.Errorf("unsupported type %s", types(u))
+}
+
+// types returns t as source code, for error messages.
+func types(t ast.Expr) string {
+	var b bytes.Buffer
+	format.Node(&b, token.NewFileSet(), t)
+	return b.String()
+}
+
+// test writes a test comparing the generated methods against Hash.
+func (g *generator) test() {
+	g.printf("// Code generated by hashstructure-gen. DO NOT EDIT.\n\n")
+	g.printf("package %s\n\n", g.pkg)
+	g.printf("import (\n\"bytes\"\n\"testing\"\n\n")
+	g.printf("\"go.openly.dev/hashstructure\"\n\"go.openly.dev/hashstructure/hashtest\"\n)\n")
+
+	for _, name := range g.cfg.Types {
+		g.printf("\nfunc Test%s_Hash%s(t *testing.T) {\n", name, g.suffix)
+		g.printf("for seed := int64(0); seed < 100; seed++ {\n")
+		g.printf("var v %s\n", name)
+		g.printf("if seed > 0 {\nhashtest.Fill(&v, seed)\n}\n\n")
+		g.printf("expected, err := hashstructure.Hash(v, %s, %s)\n", g.formatExpr(), g.optionsExpr())
+		g.printf("if err != nil {\nt.Fatalf(\"seed %%d: Failed to hash: %%s\", seed, err)\n}\n")
+		g.printf("actual, err := v.Hash%s()\n", g.suffix)
+		g.printf("if err != nil {\nt.Fatalf(\"seed %%d: Failed to hash: %%s\", seed, err)\n}\n")
+		g.printf("if !bytes.Equal(actual, expected) {\n")
+		g.printf("t.Fatalf(\"seed %%d: non-matching: %%x, %%x\", seed, actual, expected)\n}\n")
+		g.printf("}\n}\n")
+	}
+}
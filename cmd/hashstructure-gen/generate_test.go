@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.openly.dev/hashstructure"
+)
+
+func TestGenerate_example(t *testing.T) {
+	// The generated code in internal/example must be up to date. Its own
+	// tests check it against hashstructure.Hash.
+	dir := filepath.Join("internal", "example")
+	cases := []struct {
+		Output string
+		Config config
+	}{
+		{
+			"config_hashstructure.go",
+			config{Types: []string{"Config", "Section"}, Format: hashstructure.FormatV2, TagName: "hash"},
+		},
+		{
+			"config_md5_hashstructure.go",
+			config{Types: []string{"Config"}, Format: hashstructure.FormatMD5, TagName: "hash"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Output, func(t *testing.T) {
+			output := filepath.Join(dir, tc.Output)
+			files, err := parseDir(dir, output)
+			if err != nil {
+				t.Fatalf("Failed to parse: %s", err)
+			}
+
+			code, test, err := generate(files, tc.Config)
+			if err != nil {
+				t.Fatalf("Failed to generate: %s", err)
+			}
+
+			for path, actual := range map[string][]byte{
+				output: code,
+				strings.TrimSuffix(output, ".go") + "_test.go": test,
+			} {
+				expected, err := os.ReadFile(path)
+				if err != nil {
+					t.Fatalf("Failed to read: %s", err)
+				}
+				if !bytes.Equal(actual, expected) {
+					t.Fatalf("%s is out of date, run go generate", path)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerate_errors(t *testing.T) {
+	cases := []struct {
+		Src string
+		Err string
+	}{
+		{
+			`type T int`,
+			"type T is not a struct",
+		},
+		{
+			`type T struct{ F any }`,
+			"field F: unsupported type any",
+		},
+		{
+			`type T struct{ F bytes.Buffer }`,
+			"field F: unsupported type bytes.Buffer",
+		},
+		{
+			`type T struct{ F struct{ A int } }`,
+			"field F: unsupported type struct",
+		},
+		{
+			`type T struct{ F U }; type U struct{}; func (U) Hash() ([]byte, error) { return nil, nil }`,
+			"type U: types with a Hash method are not supported",
+		},
+		{
+			`type T struct{ F int ` + "`hash:\"string\"`" + ` }`,
+			"field F has hash:\"string\" set, but has no String method",
+		},
+		{
+			`type T struct{ F []byte ` + "`hash:\"binary\"`" + ` }`,
+			"hash:\"binary\" is not supported",
+		},
+		{
+			`type T struct{ F []int ` + "`hash:\"set,omitempty\"`" + ` }`,
+			"hash:\"set,omitempty\" is not supported",
+		},
+		{
+			`type T[X any] struct{ F X }`,
+			"generic types are not supported",
+		},
+		{
+			`type U struct{}`,
+			"type T not found",
+		},
+	}
+
+	for _, tc := range cases {
+		f, err := parser.ParseFile(token.NewFileSet(), "t.go", "package p\n"+tc.Src, 0)
+		if err != nil {
+			t.Fatalf("Failed to parse %q: %s", tc.Src, err)
+		}
+
+		_, _, err = generate([]*ast.File{f}, config{
+			Types:   []string{"T"},
+			Format:  hashstructure.FormatV2,
+			TagName: "hash",
+		})
+		if err == nil || !strings.Contains(err.Error(), tc.Err) {
+			t.Fatalf("%s: expected error containing %q, got %v", tc.Src, tc.Err, err)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+// Code generated by hashstructure-gen. DO NOT EDIT.
+
+package example
+
+import "go.openly.dev/hashstructure"
+
+// HashV2 returns the same hash as
+//
+//	hashstructure.Hash(v, hashstructure.FormatV2, nil)
+//
+// without reflection.
+func (v Config) HashV2() ([]byte, error) {
+	e, err := hashstructure.NewEncoder(hashstructure.FormatV2)
+	if err != nil {
+		return nil, err
+	}
+	if err := v.hashstructureV2(e); err != nil {
+		return nil, err
+	}
+	return e.Sum(), nil
+}
+
+// HashV2 returns the same hash as
+//
+//	hashstructure.Hash(v, hashstructure.FormatV2, nil)
+//
+// without reflection.
+func (v Section) HashV2() ([]byte, error) {
+	e, err := hashstructure.NewEncoder(hashstructure.FormatV2)
+	if err != nil {
+		return nil, err
+	}
+	if err := v.hashstructureV2(e); err != nil {
+		return nil, err
+	}
+	return e.Sum(), nil
+}
+
+func (v *Config) hashstructureV2(e *hashstructure.Encoder) error {
+	e.Struct("Config")
+	e.String("Base")
+	if err := v.Base.hashstructureV2(e); err != nil {
+		return err
+	}
+	e.String("Name")
+	e.String(v.Name.String())
+	e.String("Enabled")
+	e.Bool(v.Enabled)
+	e.String("Level")
+	e.Int64(int64(v.Level))
+	e.String("Ratio")
+	e.Float32(v.Ratio)
+	e.String("Timeout")
+	e.Int64(int64(v.Timeout))
+	e.String("Tags")
+	s2 := e.Set(len(v.Tags))
+	for i1 := range v.Tags {
+		e := s2.Elem()
+		e.String(v.Tags[i1])
+	}
+	s2.Close()
+	e.String("Data")
+	e.Slice(len(v.Data))
+	for i3 := range v.Data {
+		e.Uint8(v.Data[i3])
+	}
+	e.String("Checksum")
+	e.Array(len(v.Checksum))
+	for i4 := range v.Checksum {
+		e.Uint8(v.Checksum[i4])
+	}
+	e.String("Sections")
+	e.Slice(len(v.Sections))
+	for i5 := range v.Sections {
+		if err := v.Sections[i5].hashstructureV2(e); err != nil {
+			return err
+		}
+	}
+	e.String("Default")
+	if v.Default == nil {
+		e.Nil()
+	} else {
+		if err := v.Default.hashstructureV2(e); err != nil {
+			return err
+		}
+	}
+	e.String("Env")
+	m6 := e.Map(len(v.Env))
+	for k7, x8 := range v.Env {
+		ke, ve := m6.Entry()
+		ke.String(k7)
+		ve.String(x8)
+	}
+	m6.Close()
+	e.String("Matrix")
+	m9 := e.Map(len(v.Matrix))
+	for k10, x11 := range v.Matrix {
+		ke, ve := m9.Entry()
+		ke.String(k10)
+		ve.Slice(len(x11))
+		for i12 := range x11 {
+			ve.Int64(int64(x11[i12]))
+		}
+	}
+	m9.Close()
+	e.End()
+	return nil
+}
+
+func (v *Section) hashstructureV2(e *hashstructure.Encoder) error {
+	e.Struct("Part")
+	e.String("Heading")
+	e.String(v.Title)
+	e.String("Weight")
+	e.Int16(v.Weight)
+	e.String("Children")
+	m1 := e.Map(len(v.Children))
+	for k2, x3 := range v.Children {
+		ke, ve := m1.Entry()
+		ke.Int64(int64(k2))
+		if x3 == nil {
+			ve.Nil()
+		} else {
+			if err := x3.hashstructureV2(ve); err != nil {
+				return err
+			}
+		}
+	}
+	m1.Close()
+	e.String("Scale")
+	e.Complex64(v.Scale)
+	e.End()
+	return nil
+}
+
+func (v *Base) hashstructureV2(e *hashstructure.Encoder) error {
+	e.Struct("Base")
+	e.String("ID")
+	e.Uint64(v.ID)
+	e.String("Created")
+	if err := e.Time(v.Created); err != nil {
+		return err
+	}
+	e.End()
+	return nil
+}
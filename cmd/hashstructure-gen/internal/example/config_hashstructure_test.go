@@ -0,0 +1,53 @@
+// Code generated by hashstructure-gen. DO NOT EDIT.
+
+package example
+
+import (
+	"bytes"
+	"testing"
+
+	"go.openly.dev/hashstructure"
+	"go.openly.dev/hashstructure/hashtest"
+)
+
+func TestConfig_HashV2(t *testing.T) {
+	for seed := int64(0); seed < 100; seed++ {
+		var v Config
+		if seed > 0 {
+			hashtest.Fill(&v, seed)
+		}
+
+		expected, err := hashstructure.Hash(v, hashstructure.FormatV2, nil)
+		if err != nil {
+			t.Fatalf("seed %d: Failed to hash: %s", seed, err)
+		}
+		actual, err := v.HashV2()
+		if err != nil {
+			t.Fatalf("seed %d: Failed to hash: %s", seed, err)
+		}
+		if !bytes.Equal(actual, expected) {
+			t.Fatalf("seed %d: non-matching: %x, %x", seed, actual, expected)
+		}
+	}
+}
+
+func TestSection_HashV2(t *testing.T) {
+	for seed := int64(0); seed < 100; seed++ {
+		var v Section
+		if seed > 0 {
+			hashtest.Fill(&v, seed)
+		}
+
+		expected, err := hashstructure.Hash(v, hashstructure.FormatV2, nil)
+		if err != nil {
+			t.Fatalf("seed %d: Failed to hash: %s", seed, err)
+		}
+		actual, err := v.HashV2()
+		if err != nil {
+			t.Fatalf("seed %d: Failed to hash: %s", seed, err)
+		}
+		if !bytes.Equal(actual, expected) {
+			t.Fatalf("seed %d: non-matching: %x, %x", seed, actual, expected)
+		}
+	}
+}
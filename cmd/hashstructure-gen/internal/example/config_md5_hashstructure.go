@@ -0,0 +1,125 @@
+// Code generated by hashstructure-gen. DO NOT EDIT.
+
+package example
+
+import "go.openly.dev/hashstructure"
+
+// HashMD5 returns the same hash as
+//
+//	hashstructure.Hash(v, hashstructure.FormatMD5, nil)
+//
+// without reflection.
+func (v Config) HashMD5() ([]byte, error) {
+	e, err := hashstructure.NewEncoder(hashstructure.FormatMD5)
+	if err != nil {
+		return nil, err
+	}
+	if err := v.hashstructureMD5(e); err != nil {
+		return nil, err
+	}
+	return e.Sum(), nil
+}
+
+func (v *Config) hashstructureMD5(e *hashstructure.Encoder) error {
+	e.Struct("Config")
+	e.String("Base")
+	if err := v.Base.hashstructureMD5(e); err != nil {
+		return err
+	}
+	e.String("Name")
+	e.String(v.Name.String())
+	e.String("Enabled")
+	e.Bool(v.Enabled)
+	e.String("Level")
+	e.Int64(int64(v.Level))
+	e.String("Ratio")
+	e.Float32(v.Ratio)
+	e.String("Timeout")
+	e.Int64(int64(v.Timeout))
+	e.String("Tags")
+	// Sets are not hashed by hashstructure.FormatMD5
+	e.String("Data")
+	e.Slice(len(v.Data))
+	for i1 := range v.Data {
+		e.Uint8(v.Data[i1])
+	}
+	e.String("Checksum")
+	e.Array(len(v.Checksum))
+	for i2 := range v.Checksum {
+		e.Uint8(v.Checksum[i2])
+	}
+	e.String("Sections")
+	e.Slice(len(v.Sections))
+	for i3 := range v.Sections {
+		if err := v.Sections[i3].hashstructureMD5(e); err != nil {
+			return err
+		}
+	}
+	e.String("Default")
+	if v.Default == nil {
+		e.Nil()
+	} else {
+		if err := v.Default.hashstructureMD5(e); err != nil {
+			return err
+		}
+	}
+	e.String("Env")
+	m4 := e.Map(len(v.Env))
+	for k5, x6 := range v.Env {
+		ke, ve := m4.Entry()
+		ke.String(k5)
+		ve.String(x6)
+	}
+	m4.Close()
+	e.String("Matrix")
+	m7 := e.Map(len(v.Matrix))
+	for k8, x9 := range v.Matrix {
+		ke, ve := m7.Entry()
+		ke.String(k8)
+		ve.Slice(len(x9))
+		for i10 := range x9 {
+			ve.Int64(int64(x9[i10]))
+		}
+	}
+	m7.Close()
+	e.End()
+	return nil
+}
+
+func (v *Base) hashstructureMD5(e *hashstructure.Encoder) error {
+	e.Struct("Base")
+	e.String("ID")
+	e.Uint64(v.ID)
+	e.String("Created")
+	if err := e.Time(v.Created); err != nil {
+		return err
+	}
+	e.End()
+	return nil
+}
+
+func (v *Section) hashstructureMD5(e *hashstructure.Encoder) error {
+	e.Struct("Part")
+	e.String("Heading")
+	e.String(v.Title)
+	e.String("Weight")
+	e.Int16(v.Weight)
+	e.String("Children")
+	m1 := e.Map(len(v.Children))
+	for k2, x3 := range v.Children {
+		ke, ve := m1.Entry()
+		ke.Int64(int64(k2))
+		if x3 == nil {
+			ve.Nil()
+		} else {
+			if err := x3.hashstructureMD5(ve); err != nil {
+				return err
+			}
+		}
+	}
+	m1.Close()
+	e.String("Scale")
+	e.Complex64(v.Scale)
+	e.End()
+	return nil
+}
@@ -0,0 +1,32 @@
+// Code generated by hashstructure-gen. DO NOT EDIT.
+
+package example
+
+import (
+	"bytes"
+	"testing"
+
+	"go.openly.dev/hashstructure"
+	"go.openly.dev/hashstructure/hashtest"
+)
+
+func TestConfig_HashMD5(t *testing.T) {
+	for seed := int64(0); seed < 100; seed++ {
+		var v Config
+		if seed > 0 {
+			hashtest.Fill(&v, seed)
+		}
+
+		expected, err := hashstructure.Hash(v, hashstructure.FormatMD5, nil)
+		if err != nil {
+			t.Fatalf("seed %d: Failed to hash: %s", seed, err)
+		}
+		actual, err := v.HashMD5()
+		if err != nil {
+			t.Fatalf("seed %d: Failed to hash: %s", seed, err)
+		}
+		if !bytes.Equal(actual, expected) {
+			t.Fatalf("seed %d: non-matching: %x, %x", seed, actual, expected)
+		}
+	}
+}
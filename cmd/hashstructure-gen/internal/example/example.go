@@ -0,0 +1,53 @@
+// Package example holds types for testing the code generated by
+// hashstructure-gen against hashstructure.Hash.
+package example
+
+import (
+	"strings"
+	"time"
+)
+
+//go:generate go run go.openly.dev/hashstructure/cmd/hashstructure-gen -type Config,Section
+//go:generate go run go.openly.dev/hashstructure/cmd/hashstructure-gen -type Config -format md5 -output config_md5_hashstructure.go
+
+type Level int
+
+type Name string
+
+func (n Name) String() string {
+	return strings.ToUpper(string(n))
+}
+
+type Base struct {
+	ID      uint64
+	Created time.Time
+}
+
+type Config struct {
+	Base
+
+	Name     Name `hash:"string"`
+	Enabled  bool
+	Level    Level
+	Ratio    float32
+	Timeout  time.Duration
+	Tags     []string `hash:"set"`
+	Data     []byte
+	Checksum [4]byte
+	Sections []Section
+	Default  *Section
+	Env      map[string]string
+	Matrix   map[string][]int
+	Secret   string `hash:"ignore"`
+
+	internal int
+}
+
+type Section struct {
+	_ struct{} `hash:"name=Part"`
+
+	Title    string `hash:"name=Heading"`
+	Weight   int16
+	Children map[int]*Section
+	Scale    complex64
+}
@@ -0,0 +1,118 @@
+// Command hashstructure-gen generates methods that hash structs without
+// reflection, producing the same hashes as hashstructure.Hash.
+//
+// For each given type T, it generates a method
+//
+//	func (v T) HashV2() ([]byte, error)
+//
+// named after the format, that returns what hashstructure.Hash(v,
+// hashstructure.FormatV2, nil) does, along with a test asserting so. It is
+// meant to be run by go generate:
+//
+//	//go:generate go run go.openly.dev/hashstructure/cmd/hashstructure-gen -type Config,Section
+//
+// The struct types must be declared in the package, as must any structs
+// they contain, and their fields may only use the predeclared types,
+// time.Time, time.Duration, pointers, arrays, slices and maps. Types that
+// implement Hashable, HashWriter, Includable or IncludableMap, generic types
+// and fields with the "binary", "text" or "omitempty" tag directives aren't
+// supported. The generated code doesn't detect cycles, and always uses the
+// default HashOptions except for the tag name.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.openly.dev/hashstructure"
+)
+
+func main() {
+	typeNames := flag.String("type", "", "comma-separated list of type names; required")
+	formatName := flag.String("format", "v2", "hash format, such as v2 or md5")
+	tagName := flag.String("tag", "hash", "struct tag to read, as in HashOptions.TagName")
+	output := flag.String("output", "", "output file name; default <dir>/<type>_hashstructure.go")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: hashstructure-gen -type T[,T...] [flags] [dir]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *typeNames == "" || flag.NArg() > 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	dir := "."
+	if flag.NArg() == 1 {
+		dir = flag.Arg(0)
+	}
+
+	if err := run(dir, *typeNames, *formatName, *tagName, *output); err != nil {
+		fmt.Fprintf(os.Stderr, "hashstructure-gen: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(dir, typeNames, formatName, tagName, output string) error {
+	format, err := hashstructure.ParseFormat(formatName)
+	if err != nil {
+		return fmt.Errorf("unknown format %q", formatName)
+	}
+
+	cfg := config{
+		Types:   strings.Split(typeNames, ","),
+		Format:  format,
+		TagName: tagName,
+	}
+
+	if output == "" {
+		output = filepath.Join(dir, strings.ToLower(cfg.Types[0])+"_hashstructure.go")
+	}
+
+	files, err := parseDir(dir, output)
+	if err != nil {
+		return err
+	}
+
+	code, test, err := generate(files, cfg)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(output, code, 0o644); err != nil {
+		return err
+	}
+	return os.WriteFile(strings.TrimSuffix(output, ".go")+"_test.go", test, 0o644)
+}
+
+// parseDir parses the non-test Go files in dir, except for the previous
+// output of the generator.
+func parseDir(dir, output string) ([]*ast.File, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, path := range paths {
+		if strings.HasSuffix(path, "_test.go") || filepath.Clean(path) == filepath.Clean(output) {
+			continue
+		}
+
+		f, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+
+	return files, nil
+}
@@ -0,0 +1,186 @@
+// Command hashstructure hashes JSON documents the same way Go programs
+// using hashstructure do, without writing Go.
+//
+// Each document is decoded with encoding/json into map[string]any, []any,
+// string, float64, bool or nil, and hashed with hashstructure.Hash. Go
+// programs get the same hash when they hash the same document decoded into
+// an any:
+//
+//	var v any
+//	json.Unmarshal(doc, &v)
+//	hashstructure.Hash(v, hashstructure.FormatV2, nil)
+//
+// Usage:
+//
+//	hashstructure [flags] [file ...]
+//
+// With no files, or with "-", the document is read from standard input. The
+// hash of each document is printed on its own line, followed by the name of
+// the file when more than one file is given. With -ndjson, every non-blank
+// line of the input is hashed as a document of its own.
+//
+// Only JSON is read. YAML is out of scope, as decoding it needs a
+// dependency that hashstructure doesn't otherwise have; convert YAML to
+// JSON first.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"go.openly.dev/hashstructure"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// command holds the flags of a run.
+type command struct {
+	hasher   *hashstructure.Hasher
+	encoding string
+	ndjson   bool
+	names    bool
+
+	stdin  io.Reader
+	stdout io.Writer
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("hashstructure", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	formatName := flags.String("format", "v2", "hash format: md5, sha1, sha224, sha256, sha384, sha512 or v2")
+	encoding := flags.String("encoding", "hex", "output encoding: hex, base64 or uint64")
+	ndjson := flags.Bool("ndjson", false, "hash each line of the input as a separate document")
+	var opts hashstructure.HashOptions
+	flags.BoolVar(&opts.ZeroNil, "zero-nil", false, "hash nil like the zero value, as HashOptions.ZeroNil")
+	flags.BoolVar(&opts.IgnoreZeroValue, "ignore-zero", false, "ignore zero values, as HashOptions.IgnoreZeroValue")
+	flags.BoolVar(&opts.SlicesAsSets, "slices-as-sets", false, "hash arrays as sets in the v2 format, as HashOptions.SlicesAsSets")
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "usage: hashstructure [flags] [file ...]\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	format, err := hashstructure.ParseFormat(*formatName)
+	if err != nil {
+		fmt.Fprintf(stderr, "hashstructure: unknown format %q\n", *formatName)
+		return 2
+	}
+	switch *encoding {
+	case "hex", "base64", "uint64":
+	default:
+		fmt.Fprintf(stderr, "hashstructure: unknown encoding %q\n", *encoding)
+		return 2
+	}
+
+	h, err := hashstructure.NewHasher(format, &opts)
+	if err != nil {
+		fmt.Fprintf(stderr, "hashstructure: %s\n", err)
+		return 2
+	}
+
+	files := flags.Args()
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+
+	c := &command{
+		hasher:   h,
+		encoding: *encoding,
+		ndjson:   *ndjson,
+		names:    len(files) > 1,
+		stdin:    stdin,
+		stdout:   stdout,
+	}
+
+	status := 0
+	for _, name := range files {
+		if err := c.file(name); err != nil {
+			fmt.Fprintf(stderr, "hashstructure: %s: %s\n", name, err)
+			status = 1
+		}
+	}
+	return status
+}
+
+// file hashes the document or documents in the named file.
+func (c *command) file(name string) error {
+	r := c.stdin
+	if name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	if !c.ndjson {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		return c.document(name, data)
+	}
+
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, 64<<20)
+	for line := 1; s.Scan(); line++ {
+		if len(bytes.TrimSpace(s.Bytes())) == 0 {
+			continue
+		}
+		if err := c.document(name+":"+strconv.Itoa(line), s.Bytes()); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+	return s.Err()
+}
+
+// document hashes a single JSON document and prints the hash.
+func (c *command) document(name string, data []byte) error {
+	var v any
+	d := json.NewDecoder(bytes.NewReader(data))
+	if err := d.Decode(&v); err != nil {
+		return err
+	}
+	if _, err := d.Token(); err != io.EOF {
+		return errors.New("unexpected data after the JSON document")
+	}
+
+	var out string
+	if c.encoding == "uint64" {
+		n, err := c.hasher.HashUint64(v)
+		if err != nil {
+			return err
+		}
+		out = strconv.FormatUint(n, 10)
+	} else {
+		sum, err := c.hasher.Hash(v)
+		if err != nil {
+			return err
+		}
+		if c.encoding == "base64" {
+			out = base64.StdEncoding.EncodeToString(sum)
+		} else {
+			out = hex.EncodeToString(sum)
+		}
+	}
+
+	if c.names {
+		out += "  " + name
+	}
+	_, err := fmt.Fprintln(c.stdout, out)
+	return err
+}
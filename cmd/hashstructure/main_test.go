@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"go.openly.dev/hashstructure"
+)
+
+const testDoc = `{"name": "foo", "tags": ["b", "a"], "count": 3, "meta": {"x": null, "y": 0}}`
+
+// expected returns the hash of doc as computed by a Go program.
+func expected(t *testing.T, doc string, format hashstructure.Format, opts *hashstructure.HashOptions) []byte {
+	t.Helper()
+
+	var v any
+	if err := json.Unmarshal([]byte(doc), &v); err != nil {
+		t.Fatalf("Failed to decode: %s", err)
+	}
+	sum, err := hashstructure.Hash(v, format, opts)
+	if err != nil {
+		t.Fatalf("Failed to hash: %s", err)
+	}
+	return sum
+}
+
+func runCommand(t *testing.T, stdin string, args ...string) (string, string, int) {
+	t.Helper()
+
+	var stdout, stderr bytes.Buffer
+	status := run(args, strings.NewReader(stdin), &stdout, &stderr)
+	return stdout.String(), stderr.String(), status
+}
+
+func TestRun(t *testing.T) {
+	cases := []struct {
+		Args   []string
+		Format hashstructure.Format
+		Opts   *hashstructure.HashOptions
+	}{
+		{nil, hashstructure.FormatV2, nil},
+		{[]string{"-format", "md5"}, hashstructure.FormatMD5, nil},
+		{[]string{"-format", "SHA512"}, hashstructure.FormatSHA512, nil},
+		{[]string{"-zero-nil"}, hashstructure.FormatV2, &hashstructure.HashOptions{ZeroNil: true}},
+		{[]string{"-ignore-zero"}, hashstructure.FormatV2, &hashstructure.HashOptions{IgnoreZeroValue: true}},
+		{[]string{"-slices-as-sets"}, hashstructure.FormatV2, &hashstructure.HashOptions{SlicesAsSets: true}},
+	}
+
+	for _, tc := range cases {
+		stdout, stderr, status := runCommand(t, testDoc, tc.Args...)
+		if status != 0 {
+			t.Fatalf("%q: exit status %d: %s", tc.Args, status, stderr)
+		}
+
+		sum := expected(t, testDoc, tc.Format, tc.Opts)
+		if stdout != hex.EncodeToString(sum)+"\n" {
+			t.Fatalf("%q: expected %x, got %q", tc.Args, sum, stdout)
+		}
+	}
+}
+
+func TestRun_slicesAsSets(t *testing.T) {
+	hash := func(doc string, args ...string) string {
+		stdout, stderr, status := runCommand(t, doc, args...)
+		if status != 0 {
+			t.Fatalf("%q: exit status %d: %s", args, status, stderr)
+		}
+		return stdout
+	}
+
+	one := `{"tags": ["a", "b"], "nested": [[1, 2], [3]]}`
+	two := `{"tags": ["b", "a"], "nested": [[3], [2, 1]]}`
+	if hash(one, "-slices-as-sets") != hash(two, "-slices-as-sets") {
+		t.Fatal("reordered arrays should hash the same with -slices-as-sets")
+	}
+	if hash(one) == hash(two) {
+		t.Fatal("reordered arrays should hash differently without -slices-as-sets")
+	}
+
+	other := `{"tags": ["a", "c"], "nested": [[1, 2], [3]]}`
+	for _, format := range []string{"md5", "sha1", "sha224", "sha256", "sha384", "sha512", "v2"} {
+		if hash(one, "-format", format, "-slices-as-sets") == hash(other, "-format", format, "-slices-as-sets") {
+			t.Fatalf("%s: different arrays should hash differently with -slices-as-sets", format)
+		}
+	}
+}
+
+func TestRun_encoding(t *testing.T) {
+	sum := expected(t, testDoc, hashstructure.FormatV2, nil)
+
+	stdout, _, _ := runCommand(t, testDoc, "-encoding", "base64")
+	if stdout != base64.StdEncoding.EncodeToString(sum)+"\n" {
+		t.Fatalf("bad base64: %q", stdout)
+	}
+
+	stdout, _, _ = runCommand(t, testDoc, "-encoding", "uint64")
+	if stdout != strconv.FormatUint(binary.BigEndian.Uint64(sum), 10)+"\n" {
+		t.Fatalf("bad uint64: %q for %x", stdout, sum)
+	}
+}
+
+func TestRun_files(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.json")
+	b := filepath.Join(dir, "b.ndjson")
+	if err := os.WriteFile(a, []byte(testDoc), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(b, []byte("[1, 2]\n\n\"x\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// A single file is printed without its name
+	stdout, stderr, status := runCommand(t, "", a)
+	if status != 0 {
+		t.Fatalf("exit status %d: %s", status, stderr)
+	}
+	if stdout != hex.EncodeToString(expected(t, testDoc, hashstructure.FormatV2, nil))+"\n" {
+		t.Fatalf("bad output: %q", stdout)
+	}
+
+	// Each line is a document, blank lines are skipped
+	stdout, stderr, status = runCommand(t, testDoc, "-ndjson", "-", b)
+	if status != 0 {
+		t.Fatalf("exit status %d: %s", status, stderr)
+	}
+	lines := []string{
+		hex.EncodeToString(expected(t, testDoc, hashstructure.FormatV2, nil)) + "  -:1",
+		hex.EncodeToString(expected(t, "[1, 2]", hashstructure.FormatV2, nil)) + "  " + b + ":1",
+		hex.EncodeToString(expected(t, `"x"`, hashstructure.FormatV2, nil)) + "  " + b + ":3",
+	}
+	if stdout != strings.Join(lines, "\n")+"\n" {
+		t.Fatalf("bad output:\n%s", stdout)
+	}
+}
+
+func TestRun_errors(t *testing.T) {
+	cases := []struct {
+		Stdin  string
+		Args   []string
+		Status int
+		Err    string
+	}{
+		{testDoc, []string{"-format", "crc32"}, 2, "unknown format"},
+		{testDoc, []string{"-encoding", "base32"}, 2, "unknown encoding"},
+		{`{"a": `, nil, 1, "unexpected EOF"},
+		{`{} {}`, nil, 1, "unexpected data after the JSON document"},
+		{"{}\n{", []string{"-ndjson"}, 1, "line 2"},
+		{"", []string{"does-not-exist.json"}, 1, "does-not-exist.json"},
+	}
+
+	for _, tc := range cases {
+		_, stderr, status := runCommand(t, tc.Stdin, tc.Args...)
+		if status != tc.Status || !strings.Contains(stderr, tc.Err) {
+			t.Fatalf("%q: expected status %d and %q, got %d and %q", tc.Args, tc.Status, tc.Err, status, stderr)
+		}
+	}
+}
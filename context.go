@@ -0,0 +1,86 @@
+package hashstructure
+
+import (
+	"context"
+	"reflect"
+	"sync/atomic"
+)
+
+// checkInterval is the number of values visited between checks of the
+// context passed to HashContext.
+const checkInterval = 256
+
+// HashContext hashes v like Hash, but stops with an error wrapping
+// ctx.Err() when ctx is done. Along with the limits in HashOptions, this
+// bounds the time spent hashing untrusted input.
+func HashContext(ctx context.Context, v any, format Format, opts *HashOptions) ([]byte, error) {
+	h, err := NewHasher(format, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.HashContext(ctx, v)
+}
+
+// HashContext hashes v like Hash, but stops with an error wrapping
+// ctx.Err() when ctx is done.
+func (h *Hasher) HashContext(ctx context.Context, v any) ([]byte, error) {
+	w := h.rootWalker(ctx)
+	err := w.visit(reflect.ValueOf(v), nil)
+	return w.h.Sum(nil), err
+}
+
+// limits tracks the HashOptions limits and the context for a single call
+// to Hash. It is shared by all the walkers of that call.
+type limits struct {
+	// Updated atomically, as walkers may run in parallel
+	visits   int64
+	elements int64
+	bytes    int64
+
+	ctx context.Context
+}
+
+// rootWalker returns the walker for a value passed to Hash, HashContext or
+// HashTree.
+func (h *Hasher) rootWalker(ctx context.Context) *walker {
+	w := h.newWalker()
+	if (ctx != nil && ctx.Done() != nil) ||
+		h.opts.MaxDepth > 0 || h.opts.MaxElements > 0 || h.opts.MaxBytes > 0 {
+		w.limits = &limits{ctx: ctx}
+	}
+	return w
+}
+
+// checkVisit is called for every value visited, and checks the depth and
+// every so often the context.
+func (w *walker) checkVisit() error {
+	if max := w.opts.MaxDepth; max > 0 && len(w.path) > max {
+		return &ErrLimit{Limit: "MaxDepth", Max: max}
+	}
+
+	l := w.limits
+	if l.ctx != nil && atomic.AddInt64(&l.visits, 1)%checkInterval == 1 {
+		return l.ctx.Err()
+	}
+	return nil
+}
+
+// checkElements is called with the number of elements or entries of every
+// array, slice and map.
+func (w *walker) checkElements(n int) error {
+	max := w.opts.MaxElements
+	if max > 0 && atomic.AddInt64(&w.limits.elements, int64(n)) > int64(max) {
+		return &ErrLimit{Limit: "MaxElements", Max: max}
+	}
+	return nil
+}
+
+// checkBytes is called with the number of bytes about to be written.
+func (w *walker) checkBytes(n int) error {
+	max := w.opts.MaxBytes
+	if max > 0 && atomic.AddInt64(&w.limits.bytes, int64(n)) > int64(max) {
+		return &ErrLimit{Limit: "MaxBytes", Max: max}
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package hashstructure
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestHashContext(t *testing.T) {
+	v := newParallelValue(20)
+	expected, err := Hash(v, FormatV2, nil)
+	if err != nil {
+		t.Fatalf("Failed to hash: %s", err)
+	}
+
+	h, err := HashContext(context.Background(), v, FormatV2, nil)
+	if err != nil {
+		t.Fatalf("Failed to hash: %s", err)
+	}
+	if !bytes.Equal(h, expected) {
+		t.Fatalf("non-matching: %d, %d", h, expected)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, opts := range []*HashOptions{nil, {Parallelism: 4, ParallelThreshold: 1}} {
+		_, err = HashContext(ctx, v, FormatV2, opts)
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context.Canceled, got %v", err)
+		}
+	}
+}
+
+func TestHashContext_cancelDuring(t *testing.T) {
+	// Cancel from within the walk, once it is well under way
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var n int
+	opts := &HashOptions{
+		TypeHashers: map[reflect.Type]TypeHashFunc{
+			reflect.TypeOf(benchAddress{}): func(w *Writer, v any) error {
+				if n++; n == 10 {
+					cancel()
+				}
+				_, err := w.Write([]byte(v.(benchAddress).Zip))
+				return err
+			},
+		},
+	}
+
+	_, err := HashContext(ctx, newParallelValue(20), FormatV2, opts)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestHash_limits(t *testing.T) {
+	type Node struct {
+		Name     string
+		Children []Node
+	}
+
+	v := Node{
+		Name: "root",
+		Children: []Node{
+			{Name: "a", Children: []Node{{Name: "a1"}, {Name: "a2"}}},
+			{Name: "b"},
+		},
+	}
+
+	cases := []struct {
+		Opts  HashOptions
+		Limit string
+	}{
+		{HashOptions{MaxDepth: 5}, ""},
+		{HashOptions{MaxDepth: 4}, "MaxDepth"},
+		{HashOptions{MaxElements: 4}, ""},
+		{HashOptions{MaxElements: 3}, "MaxElements"},
+		{HashOptions{MaxBytes: 1 << 10}, ""},
+		{HashOptions{MaxBytes: 20}, "MaxBytes"},
+	}
+
+	for _, tc := range cases {
+		for _, format := range []Format{FormatMD5, FormatV2} {
+			_, err := Hash(v, format, &tc.Opts)
+			if tc.Limit == "" {
+				if err != nil {
+					t.Fatalf("format %d, %+v: unexpected error: %s", format, tc.Opts, err)
+				}
+				continue
+			}
+
+			var limitErr *ErrLimit
+			if !errors.As(err, &limitErr) {
+				t.Fatalf("format %d, %+v: expected *ErrLimit, got %v", format, tc.Opts, err)
+			}
+			if limitErr.Limit != tc.Limit {
+				t.Fatalf("format %d, %+v: bad limit: %s", format, tc.Opts, limitErr.Limit)
+			}
+		}
+	}
+}
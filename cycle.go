@@ -0,0 +1,70 @@
+package hashstructure
+
+import (
+	"encoding/binary"
+	"reflect"
+)
+
+// CyclePolicy determines what happens when a value refers back to itself,
+// for example a linked list with back-pointers or a tree with parent
+// pointers.
+type CyclePolicy uint
+
+const (
+	// CycleError fails the hash with an *ErrCycle. This is the default.
+	CycleError CyclePolicy = iota
+
+	// CycleBackRef hashes a reference in place of the repeated value. The
+	// reference records how many pointers up the value was first seen, so
+	// differently shaped cycles hash differently.
+	CycleBackRef
+)
+
+// ref identifies a pointer, map or slice that is being visited.
+type ref struct {
+	ptr uintptr
+	typ reflect.Type
+	len int
+}
+
+// enter pushes the pointer, map or slice v onto the stack of values being
+// visited. If v is already on the stack, it is part of a cycle and enter
+// handles it according to the CyclePolicy instead, returning false. The
+// caller must not visit v in that case and should return the error.
+//
+// Only the values on the current path are tracked, so a value that is
+// referenced twice without a cycle is hashed twice, as before.
+func (w *walker) enter(v reflect.Value) (bool, error) {
+	r := ref{ptr: v.Pointer(), typ: v.Type()}
+	if v.Kind() == reflect.Slice {
+		r.len = v.Len()
+	}
+
+	for i := len(w.stack) - 1; i >= 0; i-- {
+		if w.stack[i] != r {
+			continue
+		}
+
+		if w.opts.Cycles != CycleBackRef {
+			return false, &ErrCycle{Path: w.pathString()}
+		}
+
+		return false, w.writeBackRef(len(w.stack) - i)
+	}
+
+	w.stack = append(w.stack, r)
+	return true, nil
+}
+
+// leave pops the stack back to the given depth.
+func (w *walker) leave(depth int) {
+	w.stack = w.stack[:depth]
+}
+
+func (w *walker) writeBackRef(n int) error {
+	if w.format.canonical() {
+		return w.writeHeader(tagBackRef, n)
+	}
+
+	return binary.Write(w, binary.LittleEndian, uint64(n))
+}
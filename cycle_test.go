@@ -0,0 +1,141 @@
+package hashstructure
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+type cycleNode struct {
+	Value    int
+	Next     *cycleNode
+	Prev     *cycleNode
+	Children []*cycleNode
+}
+
+func cycleList(values ...int) *cycleNode {
+	var head, prev *cycleNode
+	for _, v := range values {
+		n := &cycleNode{Value: v, Prev: prev}
+		if prev != nil {
+			prev.Next = n
+		} else {
+			head = n
+		}
+		prev = n
+	}
+
+	return head
+}
+
+func TestHash_cycleError(t *testing.T) {
+	m := map[string]interface{}{"foo": "bar"}
+	m["self"] = m
+
+	s := []interface{}{"foo", nil}
+	s[1] = s
+
+	cases := []struct {
+		Value interface{}
+		Path  string
+	}{
+		{
+			cycleList(1, 2),
+			"Next.Prev",
+		},
+		{
+			m,
+			`["self"]`,
+		},
+		{
+			s,
+			"[1]",
+		},
+		{
+			struct{ M map[string]interface{} }{m},
+			`M["self"]`,
+		},
+	}
+
+	for _, format := range []Format{FormatMD5, FormatV2} {
+		for _, tc := range cases {
+			_, err := Hash(tc.Value, format, nil)
+
+			var errCycle *ErrCycle
+			if !errors.As(err, &errCycle) {
+				t.Fatalf("expected ErrCycle, got: %v", err)
+			}
+			if errCycle.Path != tc.Path {
+				t.Fatalf("expected path %q, got %q", tc.Path, errCycle.Path)
+			}
+		}
+	}
+}
+
+func TestHash_cycleBackRef(t *testing.T) {
+	opts := &HashOptions{Cycles: CycleBackRef}
+
+	tree := func(value int) *cycleNode {
+		root := &cycleNode{Value: value}
+		root.Children = []*cycleNode{
+			{Value: 2, Prev: root},
+			{Value: 3, Prev: root},
+		}
+		return root
+	}
+
+	cases := []struct {
+		One, Two interface{}
+		Match    bool
+	}{
+		{
+			cycleList(1, 2, 3),
+			cycleList(1, 2, 3),
+			true,
+		},
+		{
+			cycleList(1, 2, 3),
+			cycleList(1, 2, 4),
+			false,
+		},
+		{
+			tree(1),
+			tree(1),
+			true,
+		},
+		{
+			tree(1),
+			tree(2),
+			false,
+		},
+	}
+
+	for _, format := range []Format{FormatMD5, FormatV2} {
+		for _, tc := range cases {
+			one, err := Hash(tc.One, format, opts)
+			if err != nil {
+				t.Fatalf("Failed to hash %#v: %s", tc.One, err)
+			}
+			two, err := Hash(tc.Two, format, opts)
+			if err != nil {
+				t.Fatalf("Failed to hash %#v: %s", tc.Two, err)
+			}
+
+			if bytes.Equal(one, two) != tc.Match {
+				t.Fatalf("bad, expected: %#v\n\n%#v\n\n%#v", tc.Match, tc.One, tc.Two)
+			}
+		}
+	}
+}
+
+func TestHash_sharedPointerIsNotCycle(t *testing.T) {
+	shared := &cycleNode{Value: 1}
+	v := &cycleNode{
+		Value:    0,
+		Children: []*cycleNode{shared, shared},
+	}
+
+	if _, err := Hash(v, FormatV2, nil); err != nil {
+		t.Fatalf("Failed to hash %#v: %s", v, err)
+	}
+}
@@ -0,0 +1,80 @@
+package hashstructure
+
+import (
+	"bytes"
+	"sort"
+)
+
+// Diff hashes a and b and returns the paths of the values within them whose
+// hashes differ, such as "Orders[3].Items[\"sku\"].Price". See Hash for the
+// arguments. The paths are sorted, and the path of a value that differs as
+// a whole, such as a different number or a value of another type at the
+// top, is the empty string.
+//
+// As the comparison is made by hash, it honors tags, Includable,
+// IncludableMap and the options exactly as Hash does. A changed set is
+// reported as a whole, as its elements have no position to compare by.
+func Diff(a, b any, format Format, opts *HashOptions) ([]string, error) {
+	h, err := NewHasher(format, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.Diff(a, b)
+}
+
+// Diff hashes a and b and returns the paths of the values within them whose
+// hashes differ. See the package level Diff function.
+func (h *Hasher) Diff(a, b any) ([]string, error) {
+	ta, err := h.HashTree(a)
+	if err != nil {
+		return nil, err
+	}
+	tb, err := h.HashTree(b)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	diffNodes(ta, tb, &paths)
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// diffNodes appends the paths below a and b whose hashes differ to paths.
+// a and b are at the same path.
+func diffNodes(a, b *Node, paths *[]string) {
+	if bytes.Equal(a.Hash, b.Hash) {
+		return
+	}
+
+	// Only look further if the children can be compared one by one
+	if a.unordered || b.unordered || a.Type != b.Type ||
+		len(a.Children) == 0 || len(b.Children) == 0 {
+		*paths = append(*paths, a.Path)
+		return
+	}
+
+	n := len(*paths)
+	children := make(map[string]*Node, len(b.Children))
+	for _, c := range b.Children {
+		children[c.Path] = c
+	}
+	for _, ca := range a.Children {
+		if cb, ok := children[ca.Path]; ok {
+			diffNodes(ca, cb, paths)
+			delete(children, ca.Path)
+		} else {
+			*paths = append(*paths, ca.Path)
+		}
+	}
+	for path := range children {
+		*paths = append(*paths, path)
+	}
+
+	// If all the children match, the difference is in a itself, such as
+	// the length of a slice that gained a nil element.
+	if len(*paths) == n {
+		*paths = append(*paths, a.Path)
+	}
+}
@@ -0,0 +1,142 @@
+package hashstructure
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	type Inner struct {
+		Name string
+		Tags []string `hash:"set"`
+	}
+
+	type Test struct {
+		ID     string `hash:"ignore"`
+		Count  int
+		Inner  Inner
+		Items  map[string]int
+		Values []int
+		Ptr    *Inner
+	}
+
+	base := func() Test {
+		return Test{
+			ID:     "1",
+			Count:  1,
+			Inner:  Inner{Name: "foo", Tags: []string{"a", "b"}},
+			Items:  map[string]int{"a": 1, "b": 2},
+			Values: []int{1, 2, 3},
+			Ptr:    &Inner{Name: "bar"},
+		}
+	}
+
+	cases := []struct {
+		Change func(*Test)
+		Paths  []string
+	}{
+		{
+			func(v *Test) {},
+			nil,
+		},
+		{
+			func(v *Test) { v.ID = "2" },
+			nil,
+		},
+		{
+			func(v *Test) { v.Inner.Tags = []string{"b", "a"} },
+			nil,
+		},
+		{
+			func(v *Test) { v.Count = 2 },
+			[]string{"Count"},
+		},
+		{
+			func(v *Test) { v.Inner.Name = "bar" },
+			[]string{"Inner.Name"},
+		},
+		{
+			func(v *Test) { v.Items["b"] = 3 },
+			[]string{`Items["b"]`},
+		},
+		{
+			func(v *Test) { v.Items["c"] = 3 },
+			[]string{`Items["c"]`},
+		},
+		{
+			func(v *Test) { v.Values[2] = 4 },
+			[]string{"Values[2]"},
+		},
+		{
+			func(v *Test) { v.Values = append(v.Values, 4) },
+			[]string{"Values[3]"},
+		},
+		{
+			func(v *Test) { v.Ptr.Name = "foo"; v.Count = 3 },
+			[]string{"Count", "Ptr.Name"},
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			for _, format := range []Format{FormatMD5, FormatV2} {
+				a, b := base(), base()
+				tc.Change(&b)
+
+				paths, err := Diff(a, b, format, nil)
+				if err != nil {
+					t.Fatalf("Failed to diff: %s", err)
+				}
+				if !reflect.DeepEqual(paths, tc.Paths) {
+					t.Fatalf("format %d: expected %q, got %q", format, tc.Paths, paths)
+				}
+			}
+		})
+	}
+
+	// Formats before FormatV2 don't hash the contents of sets
+	a, b := base(), base()
+	b.Inner.Tags = []string{"b", "c"}
+	paths, err := Diff(a, b, FormatV2, nil)
+	if err != nil {
+		t.Fatalf("Failed to diff: %s", err)
+	}
+	if !reflect.DeepEqual(paths, []string{"Inner.Tags"}) {
+		t.Fatalf("bad paths: %q", paths)
+	}
+}
+
+func TestDiff_includable(t *testing.T) {
+	paths, err := Diff(
+		testIncludable{Value: "foo", Ignore: "bar"},
+		testIncludable{Value: "foo", Ignore: "baz"},
+		FormatV2, nil)
+	if err != nil {
+		t.Fatalf("Failed to diff: %s", err)
+	}
+	if len(paths) != 0 {
+		t.Fatalf("expected no differences, got %q", paths)
+	}
+
+	paths, err = Diff(
+		testIncludableMap{Map: map[string]string{"foo": "bar", "ignore": "1"}},
+		testIncludableMap{Map: map[string]string{"foo": "baz", "ignore": "2"}},
+		FormatV2, nil)
+	if err != nil {
+		t.Fatalf("Failed to diff: %s", err)
+	}
+	if !reflect.DeepEqual(paths, []string{`Map["foo"]`}) {
+		t.Fatalf("bad paths: %q", paths)
+	}
+}
+
+func TestDiff_root(t *testing.T) {
+	paths, err := Diff(1, "1", FormatV2, nil)
+	if err != nil {
+		t.Fatalf("Failed to diff: %s", err)
+	}
+	if !reflect.DeepEqual(paths, []string{""}) {
+		t.Fatalf("bad paths: %q", paths)
+	}
+}
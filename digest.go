@@ -0,0 +1,151 @@
+package hashstructure
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"reflect"
+)
+
+// HashUint64 returns the hash value of v as a uint64. See Hash for the
+// arguments.
+//
+// The uint64 is the first 8 bytes of the digest read as a big-endian
+// number, which is also what hash.Hash64.Sum64 returns for hashers such as
+// FNV-64 or CRC-64. Digests shorter than 8 bytes are zero-extended.
+func HashUint64(v any, format Format, opts *HashOptions) (uint64, error) {
+	h, err := NewHasher(format, opts)
+	if err != nil {
+		return 0, err
+	}
+
+	return h.HashUint64(v)
+}
+
+// HashUint64 returns the hash value of v as a uint64. See the package level
+// HashUint64 function for how the digest is converted.
+func (h *Hasher) HashUint64(v any) (uint64, error) {
+	sum, err := h.Hash(v)
+	if err != nil {
+		return 0, err
+	}
+
+	return sumUint64(sum), nil
+}
+
+func sumUint64(sum []byte) uint64 {
+	if len(sum) >= 8 {
+		return binary.BigEndian.Uint64(sum)
+	}
+
+	var buf [8]byte
+	copy(buf[8-len(sum):], sum)
+	return binary.BigEndian.Uint64(buf[:])
+}
+
+// Array is the set of fixed-size digest types that HashArray and SumArray
+// can return, one per common digest size. Digest128, Digest256 and
+// Digest512 cover MD5, SHA-256 (and FormatV2) and SHA-512; callers can
+// define their own types for the other sizes.
+type Array interface {
+	~[8]byte | ~[16]byte | ~[20]byte | ~[28]byte | ~[32]byte | ~[48]byte | ~[64]byte
+}
+
+// HashArray returns the hash value of v as a fixed-size array. Unlike the
+// []byte returned by Hash, arrays are comparable and can be used as map
+// keys. See Hash for the arguments.
+//
+// The size of A must match the size of the digest, otherwise an
+// *ErrDigestSize is returned.
+func HashArray[A Array](v any, format Format, opts *HashOptions) (A, error) {
+	var a A
+	sum, err := Hash(v, format, opts)
+	if err != nil {
+		return a, err
+	}
+
+	return SumArray[A](sum)
+}
+
+// SumArray copies a digest returned by Hash or Hasher.Hash into a
+// fixed-size array. The size of A must match the size of the digest,
+// otherwise an *ErrDigestSize is returned.
+func SumArray[A Array](sum []byte) (A, error) {
+	var a A
+	av := reflect.ValueOf(&a).Elem()
+	if av.Len() != len(sum) {
+		return a, &ErrDigestSize{Size: len(sum), ArraySize: av.Len()}
+	}
+
+	reflect.Copy(av, reflect.ValueOf(sum))
+	return a, nil
+}
+
+// Digest128 is a 128-bit digest, such as produced by FormatMD5.
+type Digest128 [16]byte
+
+// String returns the digest in hexadecimal.
+func (d Digest128) String() string {
+	return hex.EncodeToString(d[:])
+}
+
+// MarshalText implements encoding.TextMarshaler using hexadecimal.
+func (d Digest128) MarshalText() ([]byte, error) {
+	return marshalDigest(d[:])
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (d *Digest128) UnmarshalText(text []byte) error {
+	return unmarshalDigest(d[:], text)
+}
+
+// Digest256 is a 256-bit digest, such as produced by FormatSHA256 and
+// FormatV2.
+type Digest256 [32]byte
+
+// String returns the digest in hexadecimal.
+func (d Digest256) String() string {
+	return hex.EncodeToString(d[:])
+}
+
+// MarshalText implements encoding.TextMarshaler using hexadecimal.
+func (d Digest256) MarshalText() ([]byte, error) {
+	return marshalDigest(d[:])
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (d *Digest256) UnmarshalText(text []byte) error {
+	return unmarshalDigest(d[:], text)
+}
+
+// Digest512 is a 512-bit digest, such as produced by FormatSHA512.
+type Digest512 [64]byte
+
+// String returns the digest in hexadecimal.
+func (d Digest512) String() string {
+	return hex.EncodeToString(d[:])
+}
+
+// MarshalText implements encoding.TextMarshaler using hexadecimal.
+func (d Digest512) MarshalText() ([]byte, error) {
+	return marshalDigest(d[:])
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (d *Digest512) UnmarshalText(text []byte) error {
+	return unmarshalDigest(d[:], text)
+}
+
+func marshalDigest(d []byte) ([]byte, error) {
+	text := make([]byte, hex.EncodedLen(len(d)))
+	hex.Encode(text, d)
+	return text, nil
+}
+
+func unmarshalDigest(d, text []byte) error {
+	if hex.DecodedLen(len(text)) != len(d) {
+		return &ErrDigestSize{Size: hex.DecodedLen(len(text)), ArraySize: len(d)}
+	}
+
+	_, err := hex.Decode(d, text)
+	return err
+}
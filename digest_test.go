@@ -0,0 +1,97 @@
+package hashstructure
+
+import (
+	"encoding/json"
+	"errors"
+	"hash"
+	"hash/fnv"
+	"testing"
+)
+
+func TestHashUint64(t *testing.T) {
+	v := goldenStructA
+
+	// FNV-64 digests are the big-endian encoding of Sum64
+	var h64 hash.Hash64
+	opts := &HashOptions{
+		NewHash: func() hash.Hash {
+			h64 = fnv.New64a()
+			return h64
+		},
+	}
+	h, err := NewHasher(FormatV2, opts)
+	if err != nil {
+		t.Fatalf("error creating hasher: %v", err)
+	}
+
+	// A string needs no intermediate hashes, so h64 is the top-level hash
+	got, err := h.HashUint64("foo")
+	if err != nil {
+		t.Fatalf("Failed to hash: %s", err)
+	}
+	if got != h64.Sum64() {
+		t.Fatalf("expected %d, got %d", h64.Sum64(), got)
+	}
+
+	one, err := HashUint64(v, FormatMD5, nil)
+	if err != nil {
+		t.Fatalf("Failed to hash %#v: %s", v, err)
+	}
+	two, err := HashUint64(goldenStructB, FormatMD5, nil)
+	if err != nil {
+		t.Fatalf("Failed to hash %#v: %s", v, err)
+	}
+	if one != two {
+		t.Fatalf("non-matching: %d, %d", one, two)
+	}
+
+	// First 8 bytes of goldenHashes[FormatMD5][0]
+	if one != 0x73cd9a39b6c952e9 {
+		t.Fatalf("incorrect hash %x", one)
+	}
+}
+
+func TestHashArray(t *testing.T) {
+	d, err := HashArray[Digest256](goldenStructA, FormatV2, nil)
+	if err != nil {
+		t.Fatalf("Failed to hash: %s", err)
+	}
+
+	// Arrays are usable as map keys
+	m := map[Digest256]bool{d: true}
+	d2, err := HashArray[Digest256](goldenStructB, FormatV2, nil)
+	if err != nil {
+		t.Fatalf("Failed to hash: %s", err)
+	}
+	if !m[d2] {
+		t.Fatalf("non-matching: %s, %s", d, d2)
+	}
+
+	// And round-trip through JSON as hex
+	b, err := json.Marshal(map[string]Digest256{"hash": d})
+	if err != nil {
+		t.Fatalf("error marshaling: %v", err)
+	}
+	expected := `{"hash":"` + d.String() + `"}`
+	if string(b) != expected {
+		t.Fatalf("expected %s, got %s", expected, b)
+	}
+
+	var out map[string]Digest256
+	if err := json.Unmarshal(b, &out); err != nil {
+		t.Fatalf("error unmarshaling: %v", err)
+	}
+	if out["hash"] != d {
+		t.Fatalf("non-matching: %s, %s", out["hash"], d)
+	}
+
+	// The array must fit the digest
+	_, err = HashArray[Digest512](goldenStructA, FormatV2, nil)
+	var errSize *ErrDigestSize
+	if !errors.As(err, &errSize) {
+		t.Fatalf("expected ErrDigestSize, got: %v", err)
+	}
+	if errSize.Size != 32 || errSize.ArraySize != 64 {
+		t.Fatalf("bad error: %#v", errSize)
+	}
+}
@@ -0,0 +1,268 @@
+package hashstructure
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"sort"
+	"time"
+)
+
+// Encoder writes values in the encoding used by Hash, without reflection.
+// It is meant for the code generated by cmd/hashstructure-gen, which calls
+// its methods in the order Hash visits a value, so that both produce the
+// same hash. Calling them in any other order produces hashes that don't
+// match those of Hash.
+//
+// An Encoder is not safe for concurrent use.
+type Encoder struct {
+	w   *walker
+	buf [8]byte
+}
+
+// NewEncoder returns an Encoder for the given format, using the default
+// options.
+func NewEncoder(format Format) (*Encoder, error) {
+	h, err := NewHasher(format, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Encoder{w: h.rootWalker(nil)}, nil
+}
+
+// Sum returns the hash of everything written so far.
+func (e *Encoder) Sum() []byte {
+	return e.w.h.Sum(nil)
+}
+
+// The walker of an Encoder has no limits, so writes can't fail.
+func (e *Encoder) write(tag byte, p []byte) {
+	e.w.writeTag(tag)
+	e.w.Write(p)
+}
+
+// Bool writes a bool.
+func (e *Encoder) Bool(v bool) {
+	e.buf[0] = 0
+	if v {
+		e.buf[0] = 1
+	}
+	e.write(tagBool, e.buf[:1])
+}
+
+// Int8 writes an int8.
+func (e *Encoder) Int8(v int8) {
+	e.buf[0] = byte(v)
+	e.write(tagInt8, e.buf[:1])
+}
+
+// Int16 writes an int16.
+func (e *Encoder) Int16(v int16) {
+	binary.LittleEndian.PutUint16(e.buf[:], uint16(v))
+	e.write(tagInt16, e.buf[:2])
+}
+
+// Int32 writes an int32.
+func (e *Encoder) Int32(v int32) {
+	binary.LittleEndian.PutUint32(e.buf[:], uint32(v))
+	e.write(tagInt32, e.buf[:4])
+}
+
+// Int64 writes an int64. Values of type int are hashed as int64.
+func (e *Encoder) Int64(v int64) {
+	binary.LittleEndian.PutUint64(e.buf[:], uint64(v))
+	e.write(tagInt64, e.buf[:8])
+}
+
+// Uint8 writes a uint8.
+func (e *Encoder) Uint8(v uint8) {
+	e.buf[0] = v
+	e.write(tagUint8, e.buf[:1])
+}
+
+// Uint16 writes a uint16.
+func (e *Encoder) Uint16(v uint16) {
+	binary.LittleEndian.PutUint16(e.buf[:], v)
+	e.write(tagUint16, e.buf[:2])
+}
+
+// Uint32 writes a uint32.
+func (e *Encoder) Uint32(v uint32) {
+	binary.LittleEndian.PutUint32(e.buf[:], v)
+	e.write(tagUint32, e.buf[:4])
+}
+
+// Uint64 writes a uint64. Values of type uint and uintptr are hashed as
+// uint64.
+func (e *Encoder) Uint64(v uint64) {
+	binary.LittleEndian.PutUint64(e.buf[:], v)
+	e.write(tagUint64, e.buf[:8])
+}
+
+// Float32 writes a float32.
+func (e *Encoder) Float32(v float32) {
+	binary.LittleEndian.PutUint32(e.buf[:], math.Float32bits(v))
+	e.write(tagFloat32, e.buf[:4])
+}
+
+// Float64 writes a float64.
+func (e *Encoder) Float64(v float64) {
+	binary.LittleEndian.PutUint64(e.buf[:], math.Float64bits(v))
+	e.write(tagFloat64, e.buf[:8])
+}
+
+// Complex64 writes a complex64.
+func (e *Encoder) Complex64(v complex64) {
+	e.w.writeTag(tagComplex64)
+	binary.LittleEndian.PutUint32(e.buf[:], math.Float32bits(real(v)))
+	binary.LittleEndian.PutUint32(e.buf[4:], math.Float32bits(imag(v)))
+	e.w.Write(e.buf[:8])
+}
+
+// Complex128 writes a complex128.
+func (e *Encoder) Complex128(v complex128) {
+	e.w.writeTag(tagComplex128)
+	binary.LittleEndian.PutUint64(e.buf[:], math.Float64bits(real(v)))
+	e.w.Write(e.buf[:8])
+	binary.LittleEndian.PutUint64(e.buf[:], math.Float64bits(imag(v)))
+	e.w.Write(e.buf[:8])
+}
+
+// String writes a string.
+func (e *Encoder) String(v string) {
+	e.w.writeHeader(tagString, len(v))
+	e.w.Write([]byte(v))
+}
+
+// Time writes a time.Time. It fails if the time can't be marshaled, as
+// Hash does.
+func (e *Encoder) Time(v time.Time) error {
+	b, err := v.MarshalBinary()
+	if err != nil {
+		return err
+	}
+
+	e.w.writeHeader(tagTime, len(b))
+	e.w.Write(b)
+	return nil
+}
+
+// Nil writes a nil pointer or interface. Formats before FormatV2 hash it
+// like Int64(0).
+func (e *Encoder) Nil() {
+	if !e.w.format.canonical() {
+		e.Int64(0)
+		return
+	}
+	e.w.writeTag(tagNil)
+}
+
+// Struct starts a struct with the given type name. It must be followed by
+// the name and value of each field, in order, and then End.
+func (e *Encoder) Struct(name string) {
+	e.w.writeTag(tagStruct)
+	e.String(name)
+}
+
+// End ends a struct.
+func (e *Encoder) End() {
+	e.w.writeTag(tagEnd)
+}
+
+// Array starts an array of n elements, which must follow in order.
+func (e *Encoder) Array(n int) {
+	e.w.writeHeader(tagArray, n)
+}
+
+// Slice starts a slice of n elements, which must follow in order.
+func (e *Encoder) Slice(n int) {
+	e.w.writeHeader(tagSlice, n)
+}
+
+// Map starts a map of n entries. Each entry must be written to the
+// encoders returned by MapEncoder.Entry, in any order, and the map is
+// written by MapEncoder.Close.
+func (e *Encoder) Map(n int) *MapEncoder {
+	return &MapEncoder{e: e, entries: make([]*Encoder, 0, n)}
+}
+
+// Set starts a slice of n elements hashed as a set. Each element must be
+// written to an encoder returned by SetEncoder.Elem, in any order, and the
+// set is written by SetEncoder.Close.
+func (e *Encoder) Set(n int) *SetEncoder {
+	return &SetEncoder{e: e, elems: make([]*Encoder, 0, n)}
+}
+
+// MapEncoder writes a map. See Encoder.Map.
+type MapEncoder struct {
+	e       *Encoder
+	entries []*Encoder
+	values  []*Encoder
+}
+
+// Entry returns the encoders to write the key and value of an entry to.
+// The key must be written first.
+func (m *MapEncoder) Entry() (key, value *Encoder) {
+	key = &Encoder{w: m.e.w.child()}
+	m.entries = append(m.entries, key)
+	if m.e.w.format.canonical() {
+		// Entries are hashed as a whole
+		return key, key
+	}
+
+	value = &Encoder{w: m.e.w.child()}
+	m.values = append(m.values, value)
+	return key, value
+}
+
+// Close writes the map.
+func (m *MapEncoder) Close() {
+	if m.e.w.format.canonical() {
+		m.e.w.writeHeader(tagMap, len(m.entries))
+		writeSorted(m.e.w, m.entries)
+		return
+	}
+
+	writeSorted(m.e.w, m.entries)
+	writeSorted(m.e.w, m.values)
+}
+
+// SetEncoder writes a set. See Encoder.Set.
+type SetEncoder struct {
+	e     *Encoder
+	elems []*Encoder
+}
+
+// Elem returns the encoder to write an element to.
+func (s *SetEncoder) Elem() *Encoder {
+	c := &Encoder{w: s.e.w.child()}
+	s.elems = append(s.elems, c)
+	return c
+}
+
+// Close writes the set.
+func (s *SetEncoder) Close() {
+	// NOTE: formats before FormatV2 write nothing for sets, see
+	// visitSlice.
+	if !s.e.w.format.canonical() {
+		return
+	}
+
+	s.e.w.writeHeader(tagSet, len(s.elems))
+	writeSorted(s.e.w, s.elems)
+}
+
+// writeSorted writes the hashes of encs to w, sorted.
+func writeSorted(w *walker, encs []*Encoder) {
+	hashes := make([][]byte, len(encs))
+	for i, e := range encs {
+		hashes[i] = e.Sum()
+	}
+	sort.Slice(hashes, func(i, j int) bool {
+		return bytes.Compare(hashes[i], hashes[j]) < 0
+	})
+	for _, h := range hashes {
+		w.Write(h)
+	}
+}
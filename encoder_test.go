@@ -0,0 +1,107 @@
+package hashstructure
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestEncoder(t *testing.T) {
+	type Inner struct {
+		Scale complex128
+	}
+
+	type Test struct {
+		Name  string
+		Count int
+		Small int8
+		Flag  bool
+		Ratio float64
+		When  time.Time
+		Tags  []string `hash:"set"`
+		Items map[string]uint16
+		Inner *Inner
+		Nil   *Inner
+		Pair  [2]float32
+	}
+
+	v := Test{
+		Name:  "foo",
+		Count: 42,
+		Small: -3,
+		Flag:  true,
+		Ratio: 0.25,
+		When:  time.Date(2020, 1, 2, 3, 4, 5, 6, time.UTC),
+		Tags:  []string{"b", "a", "c"},
+		Items: map[string]uint16{"x": 1, "y": 2},
+		Inner: &Inner{Scale: complex(1, -1)},
+		Pair:  [2]float32{1.5, -2},
+	}
+
+	for format := FormatMD5; format < formatMax; format++ {
+		t.Run(fmt.Sprintf("format_%d", format), func(t *testing.T) {
+			expected, err := Hash(v, format, nil)
+			if err != nil {
+				t.Fatalf("Failed to hash: %s", err)
+			}
+
+			e, err := NewEncoder(format)
+			if err != nil {
+				t.Fatalf("Failed to create encoder: %s", err)
+			}
+			e.Struct("Test")
+			e.String("Name")
+			e.String(v.Name)
+			e.String("Count")
+			e.Int64(int64(v.Count))
+			e.String("Small")
+			e.Int8(v.Small)
+			e.String("Flag")
+			e.Bool(v.Flag)
+			e.String("Ratio")
+			e.Float64(v.Ratio)
+			e.String("When")
+			if err := e.Time(v.When); err != nil {
+				t.Fatalf("Failed to encode: %s", err)
+			}
+			e.String("Tags")
+			s := e.Set(len(v.Tags))
+			for _, tag := range v.Tags {
+				s.Elem().String(tag)
+			}
+			s.Close()
+			e.String("Items")
+			m := e.Map(len(v.Items))
+			for k, x := range v.Items {
+				ke, ve := m.Entry()
+				ke.String(k)
+				ve.Uint16(x)
+			}
+			m.Close()
+			e.String("Inner")
+			e.Struct("Inner")
+			e.String("Scale")
+			e.Complex128(v.Inner.Scale)
+			e.End()
+			e.String("Nil")
+			e.Nil()
+			e.String("Pair")
+			e.Array(len(v.Pair))
+			for _, f := range v.Pair {
+				e.Float32(f)
+			}
+			e.End()
+
+			if actual := e.Sum(); !bytes.Equal(actual, expected) {
+				t.Fatalf("non-matching: %d, %d", actual, expected)
+			}
+		})
+	}
+}
+
+func TestNewEncoder_format(t *testing.T) {
+	if _, err := NewEncoder(formatMax); err == nil {
+		t.Fatal("expected error")
+	}
+}
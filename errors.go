@@ -2,6 +2,8 @@ package hashstructure
 
 import (
 	"fmt"
+	"reflect"
+	"strings"
 )
 
 // ErrNotStringer is returned when there's an error with hash:"string"
@@ -14,9 +16,128 @@ func (ens *ErrNotStringer) Error() string {
 	return fmt.Sprintf("hashstructure: %s has hash:\"string\" set, but does not implement fmt.Stringer", ens.Field)
 }
 
+// ErrNotMarshaler is returned when there's an error with hash:"binary" or
+// hash:"text"
+type ErrNotMarshaler struct {
+	Field string
+
+	// Tag is "binary" or "text"
+	Tag string
+}
+
+// Error implements error for ErrNotMarshaler
+func (enm *ErrNotMarshaler) Error() string {
+	iface := "encoding.BinaryMarshaler"
+	if enm.Tag == "text" {
+		iface = "encoding.TextMarshaler"
+	}
+	return fmt.Sprintf("hashstructure: %s has hash:%q set, but does not implement %s", enm.Field, enm.Tag, iface)
+}
+
+// ErrTag is reported by Validate for a struct tag value that hashstructure
+// can't parse, such as an unknown directive. Hash ignores such values.
+type ErrTag struct {
+	Field string
+	Tag   string
+
+	// Err describes what is wrong with the value
+	Err error
+}
+
+// Error implements error for ErrTag
+func (et *ErrTag) Error() string {
+	return fmt.Sprintf("hashstructure: %s has invalid tag value %q: %s", et.Field, et.Tag, et.Err)
+}
+
+// Unwrap returns the underlying error.
+func (et *ErrTag) Unwrap() error {
+	return et.Err
+}
+
 // ErrFormat is returned when an invalid format is given to the Hash function.
 type ErrFormat struct{}
 
 func (*ErrFormat) Error() string {
 	return "format must be one of the defined Format values in the hashstructure library"
 }
+
+// ErrDigestSize is returned when a digest doesn't fit the array type it is
+// converted to.
+type ErrDigestSize struct {
+	Size      int
+	ArraySize int
+}
+
+func (e *ErrDigestSize) Error() string {
+	return fmt.Sprintf("hashstructure: digest of %d bytes does not fit an array of %d bytes", e.Size, e.ArraySize)
+}
+
+// ErrCycle is returned when a value refers back to itself and
+// HashOptions.Cycles is CycleError.
+type ErrCycle struct {
+	// Path is where the cycle was detected, such as "Next.Prev"
+	Path string
+}
+
+func (e *ErrCycle) Error() string {
+	return fmt.Sprintf("hashstructure: cycle detected at %s", e.Path)
+}
+
+// ErrLimit is returned when hashing a value exceeds one of the limits set
+// in HashOptions.
+type ErrLimit struct {
+	// Limit is the name of the option: "MaxDepth", "MaxElements" or
+	// "MaxBytes"
+	Limit string
+	Max   int
+}
+
+func (e *ErrLimit) Error() string {
+	return fmt.Sprintf("hashstructure: %s of %d exceeded", e.Limit, e.Max)
+}
+
+// ValidationError is returned by Validate. It holds a *HashError for each
+// problem found, with the path of the field in the type, where elements of
+// arrays, slices and maps are written as [].
+type ValidationError struct {
+	Errors []*HashError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// HashError is returned when a value can't be hashed. It records where in
+// the value hashing failed, so errors in deeply nested values can be traced.
+// Use errors.As to get it from the error returned by Hash.
+//
+// Cycles are reported as *ErrCycle instead, which carries its own path.
+type HashError struct {
+	// Path is the location of the value, such as Orders[3].Items["sku"].Price.
+	// It is empty for the value passed to Hash.
+	Path string
+
+	// Kind and Type describe the value. Type is nil if the value is nil.
+	Kind reflect.Kind
+	Type reflect.Type
+
+	// Err is the underlying error
+	Err error
+}
+
+func (e *HashError) Error() string {
+	if e.Path == "" {
+		return e.Err.Error()
+	}
+
+	return fmt.Sprintf("%s (at %s)", e.Err, e.Path)
+}
+
+// Unwrap returns the underlying error.
+func (e *HashError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,228 @@
+package hashstructure
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash"
+	"reflect"
+	"strconv"
+)
+
+// TypeFingerprint returns a hash of the parts of type t that determine how
+// its values are hashed with the given options: the kinds of values, the
+// names of structs and of the fields that Hash includes, their tags, and
+// whether types implement interfaces such as Hashable. The fingerprint
+// changes whenever the hash of some value of t could change because of a
+// change to t, so that stored hashes of values of t can be invalidated
+// when it does.
+//
+// Values behind interfaces are not known from the type, so the fingerprint
+// only records that there is an interface. Int and uint are recorded as
+// int64 and uint64, and pointers like the values they point to, as that's
+// how they are hashed.
+//
+// The fingerprint is computed with SHA-256, unless opts.NewHash is set.
+func TypeFingerprint(t reflect.Type, opts *HashOptions) ([]byte, error) {
+	h, err := NewHasher(FormatV2, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.TypeFingerprint(t)
+}
+
+// TypeFingerprint returns a hash of the parts of type t that determine how
+// its values are hashed by h. See the package level TypeFingerprint
+// function.
+func (h *Hasher) TypeFingerprint(t reflect.Type) ([]byte, error) {
+	if t == nil {
+		return nil, errors.New("hashstructure: TypeFingerprint of nil type")
+	}
+
+	f := &fingerprinter{hasher: h, h: h.newHash()}
+	f.typ(t, 0)
+	return f.h.Sum(nil), nil
+}
+
+type fingerprinter struct {
+	hasher *Hasher
+	h      hash.Hash
+
+	// structs holds the structs being fingerprinted, to refer back to them
+	// in recursive types
+	structs []reflect.Type
+
+	buf [binary.MaxVarintLen64]byte
+}
+
+// word writes s, prefixed by its length so that the words written are
+// self-delimiting.
+func (f *fingerprinter) word(s string) {
+	n := binary.PutUvarint(f.buf[:], uint64(len(s)))
+	f.h.Write(f.buf[:n])
+	f.h.Write([]byte(s))
+}
+
+// typ writes the fingerprint of t, found in a struct field with the given
+// tag flags.
+func (f *fingerprinter) typ(t reflect.Type, flags visitFlag) {
+	opts := &f.hasher.opts
+
+	// Pointers and interfaces are looked through, and each level can have
+	// a registered function, as in visit
+	for {
+		if _, ok := opts.TypeHashers[t]; ok {
+			f.word("custom")
+			f.word(t.String())
+			return
+		}
+
+		if t.Kind() == reflect.Interface {
+			f.word("interface")
+			return
+		}
+		if t.Kind() != reflect.Ptr {
+			break
+		}
+		t = t.Elem()
+	}
+
+	// Marshalers are used depending on the tag or the options, and on
+	// whether the value is addressable. As in visitMarshaler, tags apply
+	// to time.Time too, but UseMarshaler doesn't.
+	marshal := flags & (visitFlagBinary | visitFlagText)
+	if marshal != 0 || (opts.UseMarshaler && t != timeType) {
+		f.word("marshaler")
+		f.word(strconv.Itoa(int(marshal)))
+		f.implements(t, binaryMarshalerType, textMarshalerType)
+	}
+
+	if t == timeType {
+		f.word("time")
+		return
+	}
+
+	switch k := t.Kind(); k {
+	case reflect.Int:
+		f.word(reflect.Int64.String())
+
+	case reflect.Uint, reflect.Uintptr:
+		f.word(reflect.Uint64.String())
+
+	case reflect.Array:
+		f.word(k.String())
+		f.word(strconv.Itoa(t.Len()))
+		f.typ(t.Elem(), 0)
+
+	case reflect.Slice:
+		if flags&visitFlagSet != 0 || (opts.SlicesAsSets && f.hasher.format.canonical()) {
+			f.word("set")
+		} else {
+			f.word(k.String())
+		}
+		f.typ(t.Elem(), 0)
+
+	case reflect.Map:
+		f.word(k.String())
+		f.typ(t.Key(), 0)
+		f.typ(t.Elem(), 0)
+
+	case reflect.Struct:
+		f.structType(t)
+
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		f.word(k.String())
+		f.word(strconv.Itoa(int(opts.Unsupported)))
+
+	default:
+		f.word(k.String())
+	}
+}
+
+// implements writes which of the interfaces t and a pointer to t
+// implement.
+func (f *fingerprinter) implements(t reflect.Type, ifaces ...reflect.Type) {
+	ptr := reflect.PtrTo(t)
+	var b []byte
+	for _, iface := range ifaces {
+		b = strconv.AppendBool(b, t.Implements(iface))
+		b = strconv.AppendBool(b, ptr.Implements(iface))
+	}
+	f.word(string(b))
+}
+
+func (f *fingerprinter) structType(t reflect.Type) {
+	for i, s := range f.structs {
+		if s == t {
+			f.word("ref")
+			f.word(strconv.Itoa(len(f.structs) - i))
+			return
+		}
+	}
+	f.structs = append(f.structs, t)
+	defer func() { f.structs = f.structs[:len(f.structs)-1] }()
+
+	plan := f.hasher.structPlan(t)
+	f.word(reflect.Struct.String())
+	f.word(plan.nameValue.String())
+	f.implements(t, includableType, includableMapType, hashableType, hashWriterType)
+	if plan.optional {
+		f.word("optional")
+		return
+	}
+	if plan.hashable || plan.hashWriter {
+		// The fields are never looked at
+		return
+	}
+
+	opts := &f.hasher.opts
+	for _, field := range plan.fields {
+		ft := t.Field(field.index).Type
+		if opts.Unsupported == UnsupportedSkip && unsupportedType(ft) {
+			continue
+		}
+
+		f.word(field.nameValue.String())
+		if field.omitEmpty {
+			f.word("omitempty")
+		}
+		if field.str || opts.UseStringer {
+			if field.stringer {
+				f.word("stringer")
+				continue
+			}
+			if field.str {
+				// Only interfaces holding a Stringer can be hashed
+				f.word("string")
+			}
+		}
+
+		var flags visitFlag
+		if field.set {
+			flags |= visitFlagSet
+		}
+		if field.binary {
+			flags |= visitFlagBinary
+		}
+		if field.text {
+			flags |= visitFlagText
+		}
+		f.typ(ft, flags)
+	}
+	f.word("end")
+}
+
+// unsupportedType reports whether values of type t, after dereferencing
+// pointers, are always of a kind that can't be hashed by content.
+func unsupportedType(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,342 @@
+package hashstructure
+
+import (
+	"bytes"
+	"hash"
+	"hash/fnv"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTypeFingerprint(t *testing.T) {
+	// Each version declares its own type T, so that the names match
+	base := func() reflect.Type {
+		type T struct {
+			Name  string
+			Tags  []string `hash:"set"`
+			Count int
+			Inner struct{ A int }
+		}
+		return reflect.TypeOf(T{})
+	}
+
+	same := []func() reflect.Type{
+		func() reflect.Type {
+			type U struct {
+				_ struct{} `hash:"name=T"`
+
+				Title  string   `hash:"name=Name"`
+				Labels []string `hash:"set,name=Tags"`
+				Count  int
+				Inner  struct{ A int }
+			}
+			return reflect.TypeOf(U{})
+		},
+		func() reflect.Type {
+			type T struct {
+				Name  string
+				Tags  []string `hash:"set"`
+				Count int
+				Inner struct{ A int }
+
+				unexported int
+			}
+			return reflect.TypeOf(T{})
+		},
+		func() reflect.Type {
+			type T struct {
+				Name    string
+				Tags    []string `hash:"set"`
+				Count   int
+				Inner   struct{ A int }
+				Ignored string `hash:"ignore"`
+			}
+			return reflect.TypeOf(T{})
+		},
+		func() reflect.Type {
+			type T struct {
+				Name  *string
+				Tags  []string `hash:"set"`
+				Count int64
+				Inner *struct{ A int }
+			}
+			return reflect.TypeOf(T{})
+		},
+		func() reflect.Type {
+			type T struct {
+				Name  string
+				Tags  []string `hash:"set"`
+				Count int
+				Inner struct{ A int }
+			}
+			return reflect.TypeOf(&T{})
+		},
+	}
+
+	different := []func() reflect.Type{
+		func() reflect.Type {
+			type T struct {
+				Name  string
+				Tags  []string `hash:"set"`
+				Count int
+				Inner struct{ A int }
+				Extra bool
+			}
+			return reflect.TypeOf(T{})
+		},
+		func() reflect.Type {
+			type T struct {
+				Name  string
+				Tags  []string
+				Count int
+				Inner struct{ A int }
+			}
+			return reflect.TypeOf(T{})
+		},
+		func() reflect.Type {
+			type T struct {
+				Name  string
+				Tags  []string `hash:"set"`
+				Total int
+				Inner struct{ A int }
+			}
+			return reflect.TypeOf(T{})
+		},
+		func() reflect.Type {
+			type T struct {
+				Name  string
+				Tags  []string `hash:"set"`
+				Count uint
+				Inner struct{ A int }
+			}
+			return reflect.TypeOf(T{})
+		},
+		func() reflect.Type {
+			type T struct {
+				Name  string
+				Tags  []string `hash:"set"`
+				Count int
+				Inner struct{ A string }
+			}
+			return reflect.TypeOf(T{})
+		},
+		func() reflect.Type {
+			type T struct {
+				Name  string
+				Tags  []string `hash:"set,omitempty"`
+				Count int
+				Inner struct{ A int }
+			}
+			return reflect.TypeOf(T{})
+		},
+		func() reflect.Type {
+			type U struct {
+				Name  string
+				Tags  []string `hash:"set"`
+				Count int
+				Inner struct{ A int }
+			}
+			return reflect.TypeOf(U{})
+		},
+	}
+
+	expected, err := TypeFingerprint(base(), nil)
+	if err != nil {
+		t.Fatalf("Failed to fingerprint: %s", err)
+	}
+	if len(expected) != 32 {
+		t.Fatalf("expected a SHA-256 digest, got %d bytes", len(expected))
+	}
+
+	for i, fn := range same {
+		actual, err := TypeFingerprint(fn(), nil)
+		if err != nil {
+			t.Fatalf("Failed to fingerprint: %s", err)
+		}
+		if !bytes.Equal(actual, expected) {
+			t.Fatalf("same %d: fingerprint of %s should match", i, fn())
+		}
+	}
+
+	for i, fn := range different {
+		actual, err := TypeFingerprint(fn(), nil)
+		if err != nil {
+			t.Fatalf("Failed to fingerprint: %s", err)
+		}
+		if bytes.Equal(actual, expected) {
+			t.Fatalf("different %d: fingerprint of %s should not match", i, fn())
+		}
+	}
+}
+
+func TestTypeFingerprint_options(t *testing.T) {
+	type T struct {
+		Name string
+		Hook func()
+		Tags []string
+	}
+	typ := reflect.TypeOf(T{})
+
+	plain, err := TypeFingerprint(typ, nil)
+	if err != nil {
+		t.Fatalf("Failed to fingerprint: %s", err)
+	}
+
+	for _, opts := range []*HashOptions{
+		{Unsupported: UnsupportedSkip},
+		{SlicesAsSets: true},
+		{TagName: "other"},
+	} {
+		fp, err := TypeFingerprint(typ, opts)
+		if err != nil {
+			t.Fatalf("Failed to fingerprint: %s", err)
+		}
+		if opts.TagName != "" {
+			if !bytes.Equal(fp, plain) {
+				t.Fatalf("%+v: fingerprint should match", opts)
+			}
+			continue
+		}
+		if bytes.Equal(fp, plain) {
+			t.Fatalf("%+v: fingerprint should not match", opts)
+		}
+	}
+
+	fp, err := TypeFingerprint(typ, &HashOptions{NewHash: func() hash.Hash { return fnv.New64() }})
+	if err != nil {
+		t.Fatalf("Failed to fingerprint: %s", err)
+	}
+	if len(fp) != 8 {
+		t.Fatalf("expected an FNV-64 digest, got %d bytes", len(fp))
+	}
+}
+
+func TestTypeFingerprint_marshalerTags(t *testing.T) {
+	// Each version declares its own type T, so that the names match
+	types := []func() reflect.Type{
+		func() reflect.Type {
+			type T struct{ When time.Time }
+			return reflect.TypeOf(T{})
+		},
+		func() reflect.Type {
+			type T struct {
+				When time.Time `hash:"binary"`
+			}
+			return reflect.TypeOf(T{})
+		},
+		func() reflect.Type {
+			type T struct {
+				When time.Time `hash:"text"`
+			}
+			return reflect.TypeOf(T{})
+		},
+	}
+
+	// Hash and TypeFingerprint must agree on which types hash alike
+	when := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	for i, a := range types {
+		for j, b := range types[i+1:] {
+			fpA, err := TypeFingerprint(a(), nil)
+			if err != nil {
+				t.Fatalf("Failed to fingerprint: %s", err)
+			}
+			fpB, err := TypeFingerprint(b(), nil)
+			if err != nil {
+				t.Fatalf("Failed to fingerprint: %s", err)
+			}
+
+			va := reflect.New(a()).Elem()
+			va.Field(0).Set(reflect.ValueOf(when))
+			vb := reflect.New(b()).Elem()
+			vb.Field(0).Set(reflect.ValueOf(when))
+			hashA, err := Hash(va.Interface(), FormatV2, nil)
+			if err != nil {
+				t.Fatalf("Failed to hash: %s", err)
+			}
+			hashB, err := Hash(vb.Interface(), FormatV2, nil)
+			if err != nil {
+				t.Fatalf("Failed to hash: %s", err)
+			}
+
+			if bytes.Equal(hashA, hashB) {
+				t.Fatalf("%d and %d: expected hashes to differ", i, i+1+j)
+			}
+			if bytes.Equal(fpA, fpB) {
+				t.Fatalf("%d and %d: expected fingerprints to differ", i, i+1+j)
+			}
+		}
+	}
+
+	// UseMarshaler doesn't change how time.Time is hashed
+	plain, err := TypeFingerprint(timeType, nil)
+	if err != nil {
+		t.Fatalf("Failed to fingerprint: %s", err)
+	}
+	marshal, err := TypeFingerprint(timeType, &HashOptions{UseMarshaler: true})
+	if err != nil {
+		t.Fatalf("Failed to fingerprint: %s", err)
+	}
+	if !bytes.Equal(plain, marshal) {
+		t.Fatal("UseMarshaler should not change the fingerprint of time.Time")
+	}
+}
+
+func TestTypeFingerprint_interfaceHasher(t *testing.T) {
+	type T struct {
+		Shape testShape
+	}
+	typ := reflect.TypeOf(T{})
+	opts := &HashOptions{
+		TypeHashers: map[reflect.Type]TypeHashFunc{
+			reflect.TypeOf((*testShape)(nil)).Elem(): func(w *Writer, v any) error {
+				return w.WriteValue(v.(testShape).Area())
+			},
+		},
+	}
+
+	plain, err := TypeFingerprint(typ, nil)
+	if err != nil {
+		t.Fatalf("Failed to fingerprint: %s", err)
+	}
+	custom, err := TypeFingerprint(typ, opts)
+	if err != nil {
+		t.Fatalf("Failed to fingerprint: %s", err)
+	}
+	if bytes.Equal(plain, custom) {
+		t.Fatal("fingerprints should not match")
+	}
+
+	// As the hashes don't
+	v := T{Shape: testSquare{Side: 2}}
+	hashPlain, err := Hash(v, FormatV2, nil)
+	if err != nil {
+		t.Fatalf("Failed to hash: %s", err)
+	}
+	hashCustom, err := Hash(v, FormatV2, opts)
+	if err != nil {
+		t.Fatalf("Failed to hash: %s", err)
+	}
+	if bytes.Equal(hashPlain, hashCustom) {
+		t.Fatal("hashes should not match")
+	}
+}
+
+func TestTypeFingerprint_recursive(t *testing.T) {
+	a, err := TypeFingerprint(reflect.TypeOf(cycleNode{}), nil)
+	if err != nil {
+		t.Fatalf("Failed to fingerprint: %s", err)
+	}
+
+	b, err := TypeFingerprint(reflect.TypeOf(testHashable{}), nil)
+	if err != nil {
+		t.Fatalf("Failed to fingerprint: %s", err)
+	}
+	if bytes.Equal(a, b) {
+		t.Fatal("fingerprints should not match")
+	}
+
+	if _, err := TypeFingerprint(nil, nil); err == nil {
+		t.Fatal("expected error")
+	}
+}
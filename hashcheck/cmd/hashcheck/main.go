@@ -0,0 +1,17 @@
+// Command hashcheck checks the struct tags read by hashstructure. See the
+// hashcheck package for what it reports.
+//
+// It can be run on its own, or by go vet:
+//
+//	go vet -vettool=$(which hashcheck) ./...
+package main
+
+import (
+	"golang.org/x/tools/go/analysis/singlechecker"
+
+	"go.openly.dev/hashstructure/hashcheck"
+)
+
+func main() {
+	singlechecker.Main(hashcheck.Analyzer)
+}
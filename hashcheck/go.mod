@@ -0,0 +1,19 @@
+// hashcheck is a separate module, so that hashstructure doesn't depend on
+// golang.org/x/tools. To build it against a local copy of hashstructure,
+// create a workspace in the parent directory with: go work init . ./hashcheck
+//
+// CI always tests it that way, against the hashstructure it's checked out
+// with. The requirement below only matters to users installing hashcheck on
+// its own, and is bumped to the first tagged release of hashstructure that
+// includes internal/tag once there is one.
+module go.openly.dev/hashstructure/hashcheck
+
+go 1.23.0
+
+require go.openly.dev/hashstructure v0.0.0-20261016125808-c339422d2d0c
+
+require (
+	golang.org/x/mod v0.27.0 // indirect
+	golang.org/x/sync v0.16.0 // indirect
+	golang.org/x/tools v0.36.0
+)
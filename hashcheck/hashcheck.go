@@ -0,0 +1,238 @@
+// Package hashcheck defines an Analyzer that checks the struct tags read
+// by hashstructure.
+//
+// Mistakes in these tags either fail at runtime, when the first value of
+// the type is hashed, or not at all: unknown tag values are ignored by
+// hashstructure, so a typo such as `hash:"ignroe"` silently leaves the
+// field in the hash. The analyzer reports:
+//
+//   - invalid tag values, such as unknown or conflicting directives
+//   - "set" on fields that aren't slices, unless hashed as a string
+//   - "string" on fields that don't implement fmt.Stringer
+//   - "binary" and "text" on fields that don't implement
+//     encoding.BinaryMarshaler and encoding.TextMarshaler
+//   - tags on unexported fields, which are never hashed, and blank fields
+//     that set more than the type name
+//   - fields hashed under the same name, because of the name directive
+//
+// The -tag flag sets the tag to check, for code that sets
+// HashOptions.TagName.
+package hashcheck
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"reflect"
+	"strconv"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+
+	"go.openly.dev/hashstructure/internal/tag"
+)
+
+// Analyzer checks the struct tags read by hashstructure.
+var Analyzer = &analysis.Analyzer{
+	Name:     "hashcheck",
+	Doc:      "check struct tags read by hashstructure",
+	URL:      "https://pkg.go.dev/go.openly.dev/hashstructure/hashcheck",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+var tagName string
+
+func init() {
+	Analyzer.Flags.StringVar(&tagName, "tag", "hash", "struct tag to check, as in HashOptions.TagName")
+}
+
+var (
+	errorType = types.Universe.Lookup("error").Type()
+	bytesType = types.NewSlice(types.Typ[types.Byte])
+
+	stringerType = newInterface("String", types.Typ[types.String])
+	binaryType   = newInterface("MarshalBinary", bytesType, errorType)
+	textType     = newInterface("MarshalText", bytesType, errorType)
+)
+
+// newInterface returns an interface with a single method without
+// parameters.
+func newInterface(name string, results ...types.Type) *types.Interface {
+	vars := make([]*types.Var, len(results))
+	for i, t := range results {
+		vars[i] = types.NewVar(token.NoPos, nil, "", t)
+	}
+	sig := types.NewSignatureType(nil, nil, nil, nil, types.NewTuple(vars...), false)
+	return types.NewInterfaceType([]*types.Func{types.NewFunc(token.NoPos, nil, name, sig)}, nil).Complete()
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	inspect.Preorder([]ast.Node{(*ast.StructType)(nil)}, func(n ast.Node) {
+		st := n.(*ast.StructType)
+		for _, field := range st.Fields.List {
+			checkField(pass, field)
+		}
+		checkNames(pass, st)
+	})
+
+	return nil, nil
+}
+
+// lookup returns the value of the tag of field, if it has one.
+func lookup(field *ast.Field) (string, bool) {
+	if field.Tag == nil {
+		return "", false
+	}
+	raw, err := strconv.Unquote(field.Tag.Value)
+	if err != nil {
+		return "", false
+	}
+	return reflect.StructTag(raw).Lookup(tagName)
+}
+
+func checkField(pass *analysis.Pass, field *ast.Field) {
+	value, ok := lookup(field)
+	if !ok {
+		return
+	}
+
+	t, err := tag.Parse(value)
+	if err != nil {
+		pass.Reportf(field.Tag.Pos(), "invalid %s tag %q: %s", tagName, value, err)
+		return
+	}
+
+	if blank(field) {
+		if !t.NameOnly() {
+			pass.Reportf(field.Tag.Pos(), "only name can be set on a blank field, as in %s:\"name=T\"", tagName)
+		}
+		return
+	}
+
+	if !exported(field) {
+		pass.Reportf(field.Tag.Pos(), "%s tag on unexported field has no effect", tagName)
+		return
+	}
+
+	typ := pass.TypesInfo.TypeOf(field.Type)
+	if typ == nil || types.IsInterface(typ) {
+		// Interfaces depend on the value they hold
+		return
+	}
+
+	if t.Set && !t.String && !isSlice(typ) {
+		pass.Reportf(field.Tag.Pos(), "%s:\"set\" only applies to slices, not %s", tagName, typ)
+	}
+	if t.String && !types.Implements(typ, stringerType) {
+		pass.Reportf(field.Tag.Pos(), "%s:\"string\" is set, but %s does not implement fmt.Stringer", tagName, typ)
+	}
+	if t.Binary && !implements(typ, binaryType) {
+		pass.Reportf(field.Tag.Pos(), "%s:\"binary\" is set, but %s does not implement encoding.BinaryMarshaler", tagName, typ)
+	}
+	if t.Text && !implements(typ, textType) {
+		pass.Reportf(field.Tag.Pos(), "%s:\"text\" is set, but %s does not implement encoding.TextMarshaler", tagName, typ)
+	}
+}
+
+// blank reports whether field is a blank field, which can set the name of
+// the struct type.
+func blank(field *ast.Field) bool {
+	return len(field.Names) == 1 && field.Names[0].Name == "_"
+}
+
+// checkNames reports fields of st that are hashed under the same name,
+// which the name directive makes possible.
+func checkNames(pass *analysis.Pass, st *ast.StructType) {
+	seen := make(map[string]string)
+	for _, field := range st.Fields.List {
+		// Invalid tags are ignored, as by hashstructure
+		var t tag.Tag
+		if value, ok := lookup(field); ok {
+			t, _ = tag.Parse(value)
+		}
+		if t.Ignore {
+			continue
+		}
+
+		for _, ident := range names(field) {
+			if !ident.IsExported() {
+				continue
+			}
+			name := ident.Name
+			if t.Name != "" {
+				name = t.Name
+			}
+
+			if prev, ok := seen[name]; ok {
+				pass.Reportf(field.Pos(), "field %s is hashed under the name %q, as is field %s", ident.Name, name, prev)
+				continue
+			}
+			seen[name] = ident.Name
+		}
+	}
+}
+
+// names returns the names of field. Embedded fields are named after their
+// type.
+func names(field *ast.Field) []*ast.Ident {
+	if len(field.Names) > 0 {
+		return field.Names
+	}
+
+	t := field.Type
+	for {
+		switch tt := t.(type) {
+		case *ast.StarExpr:
+			t = tt.X
+		case *ast.IndexExpr:
+			t = tt.X
+		case *ast.IndexListExpr:
+			t = tt.X
+		case *ast.Ident:
+			return []*ast.Ident{tt}
+		case *ast.SelectorExpr:
+			return []*ast.Ident{tt.Sel}
+		default:
+			return nil
+		}
+	}
+}
+
+// exported reports whether any of the names of field is exported.
+func exported(field *ast.Field) bool {
+	idents := names(field)
+	if idents == nil {
+		return true
+	}
+
+	for _, name := range idents {
+		if name.IsExported() {
+			return true
+		}
+	}
+	return false
+}
+
+// isSlice reports whether t, after dereferencing pointers, is a slice.
+func isSlice(t types.Type) bool {
+	for {
+		p, ok := t.Underlying().(*types.Pointer)
+		if !ok {
+			break
+		}
+		t = p.Elem()
+	}
+
+	_, ok := t.Underlying().(*types.Slice)
+	return ok
+}
+
+// implements reports whether t or a pointer to t implements iface, as
+// hashstructure uses the pointer when the value is addressable.
+func implements(t types.Type, iface *types.Interface) bool {
+	return types.Implements(t, iface) || types.Implements(types.NewPointer(t), iface)
+}
@@ -0,0 +1,20 @@
+package hashcheck
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), Analyzer, "a")
+}
+
+func TestAnalyzer_tag(t *testing.T) {
+	if err := Analyzer.Flags.Set("tag", "other"); err != nil {
+		t.Fatal(err)
+	}
+	defer Analyzer.Flags.Set("tag", "hash")
+
+	analysistest.Run(t, analysistest.TestData(), Analyzer, "b")
+}
@@ -0,0 +1,74 @@
+package a
+
+import (
+	"fmt"
+	"net"
+	"time"
+)
+
+type Name string
+
+func (n Name) String() string { return string(n) }
+
+type PtrName string
+
+func (n *PtrName) String() string { return string(*n) }
+
+type Valid struct {
+	_ struct{} `hash:"name=V"`
+
+	Ignored  string       `hash:"ignore"`
+	Dash     string       `hash:"-"`
+	Tags     []string     `hash:"set"`
+	PtrTags  *[]int       `hash:"set"`
+	Name     Name         `hash:"string"`
+	Stringer fmt.Stringer `hash:"string"`
+	Any      any          `hash:"set"`
+	When     time.Time    `hash:"binary"`
+	IP       net.IP       `hash:"text"`
+	Empty    string       `hash:""`
+	Other    string       `json:"other"`
+	Plain    string
+
+	SetOmit    []string `hash:"set,omitempty"`
+	StringOmit Name     `hash:"string,omitempty"`
+	StringSet  Name     `hash:"string,set"`
+	Renamed    []string `hash:"name=tags,set"`
+}
+
+type Invalid struct {
+	Typo     string            `hash:"ignroe"` // want `invalid hash tag "ignroe": unknown directive "ignroe"`
+	Upper    []string          `hash:"Set"`    // want `invalid hash tag "Set": unknown directive "Set"`
+	Map      map[string]string `hash:"set"`    // want `hash:"set" only applies to slices, not map\[string\]string`
+	Array    [2]int            `hash:"set"`    // want `hash:"set" only applies to slices, not \[2\]int`
+	Count    int               `hash:"string"` // want `hash:"string" is set, but int does not implement fmt.Stringer`
+	PtrName  PtrName           `hash:"string"` // want `hash:"string" is set, but a.PtrName does not implement fmt.Stringer`
+	Data     []byte            `hash:"binary"` // want `hash:"binary" is set, but \[\]byte does not implement encoding.BinaryMarshaler`
+	Duration time.Duration     `hash:"text"`   // want `hash:"text" is set, but time.Duration does not implement encoding.TextMarshaler`
+	hidden   []string          `hash:"set"`    // want `hash tag on unexported field has no effect`
+
+	Conflict  time.Time   `hash:"binary,text"`    // want `invalid hash tag "binary,text": only one of string, binary and text can be set`
+	Duplicate []int       `hash:"set,set"`        // want `invalid hash tag "set,set": duplicate directive "set"`
+	Space     []int       `hash:"set, omitempty"` // want `invalid hash tag "set, omitempty": unknown directive " omitempty"`
+	MapOmit   map[int]int `hash:"omitempty,set"`  // want `hash:"set" only applies to slices, not map\[int\]int`
+
+	_        struct{} `hash:"set"`        // want `only name can be set on a blank field, as in hash:"name=T"`
+	_        struct{} `hash:"name=I,set"` // want `only name can be set on a blank field`
+	NoName   string   `hash:"name"`       // want `invalid hash tag "name": "name" needs a value, as in name=id`
+	NameTypo string   `hash:"nmae=x"`     // want `invalid hash tag "nmae=x": unknown directive "nmae"`
+
+	Anonymous struct {
+		Nested string `hash:"sett"` // want `invalid hash tag "sett": unknown directive "sett"`
+	}
+}
+
+type Names struct {
+	ID     string
+	Key    string `hash:"name=ID"` // want `field Key is hashed under the name "ID", as is field ID`
+	Old    string `hash:"ignore"`
+	Legacy string `hash:"name=Old"`
+	A, B   int    `hash:"name=AB"` // want `field B is hashed under the name "AB", as is field A`
+	Name
+	Other  int `hash:"name=Name"` // want `field Other is hashed under the name "Name", as is field Name`
+	hidden int `hash:"name=ID"`   // want `hash tag on unexported field has no effect`
+}
@@ -0,0 +1,6 @@
+package b
+
+type T struct {
+	Default string `hash:"ignroe"`
+	Custom  string `other:"ignroe"` // want `invalid other tag "ignroe": unknown directive "ignroe"`
+}
@@ -0,0 +1,264 @@
+package hashstructure
+
+import (
+	"fmt"
+	"hash"
+	"reflect"
+	"sync"
+
+	"go.openly.dev/hashstructure/internal/tag"
+)
+
+// Hasher hashes values with a fixed Format and HashOptions.
+//
+// Hashing a struct requires inspecting its fields, tags and the interfaces
+// it implements. A Hasher does this once per type and caches the result, so
+// reusing a Hasher is considerably cheaper than calling Hash for each value.
+// A Hasher is safe for concurrent use.
+type Hasher struct {
+	format Format
+	opts   HashOptions
+	tag    string
+
+	// plans caches a *structPlan per reflect.Type
+	plans sync.Map
+
+	// workers holds a token for each goroutine hashing in parallel,
+	// besides the calling ones. It is nil without HashOptions.Parallelism.
+	workers chan struct{}
+}
+
+// NewHasher returns a Hasher for the given format and options. The options
+// are copied, so later changes to opts do not affect the Hasher.
+//
+// If opts is nil, then default options will be used. See HashOptions
+// for the default values.
+func NewHasher(format Format, opts *HashOptions) (*Hasher, error) {
+	// Validate our format
+	if format <= formatInvalid || format >= formatMax {
+		return nil, &ErrFormat{}
+	}
+
+	h := &Hasher{format: format}
+
+	// Create default options
+	if opts != nil {
+		h.opts = *opts
+	}
+
+	// Copy the registry so it can't change under us
+	if h.opts.TypeHashers != nil {
+		h.opts.TypeHashers = make(map[reflect.Type]TypeHashFunc, len(opts.TypeHashers))
+		for t, fn := range opts.TypeHashers {
+			h.opts.TypeHashers[t] = fn
+		}
+	}
+
+	if h.opts.Parallelism > 1 {
+		h.workers = make(chan struct{}, h.opts.Parallelism-1)
+	}
+
+	h.tag = h.opts.TagName
+	if h.tag == "" {
+		h.tag = "hash"
+	}
+
+	return h, nil
+}
+
+// Hash returns the hash value of v. See the package level Hash function for
+// how values are hashed.
+func (h *Hasher) Hash(v any) ([]byte, error) {
+	w := h.rootWalker(nil)
+	err := w.visit(reflect.ValueOf(v), nil)
+	return w.h.Sum(nil), err
+}
+
+// hashValue returns the hash of v on its own, for example an element of a
+// set. e is the step from the value being visited to v.
+func (w *walker) hashValue(v reflect.Value, e pathElem) ([]byte, error) {
+	c := w.child(e)
+	if w.tree {
+		c.startTree(v)
+	}
+
+	err := c.visit(v, nil)
+	sum := c.h.Sum(nil)
+	if w.tree {
+		w.attach(c.finishTree(sum))
+	}
+	return sum, err
+}
+
+// hashEntry returns the hash of a single map entry, covering both the key
+// and the value.
+func (w *walker) hashEntry(k, v reflect.Value) ([]byte, error) {
+	c := w.child(keyElem(k))
+	if w.tree {
+		c.startTree(v)
+	}
+
+	if err := c.visit(k, nil); err != nil {
+		return nil, err
+	}
+	err := c.visit(v, nil)
+	sum := c.h.Sum(nil)
+	if w.tree {
+		w.attach(c.finishTree(sum))
+	}
+	return sum, err
+}
+
+// child returns a walker for a value nested under the value being visited
+// that has to be hashed separately. It continues from the path and stack
+// of w, extended by e if given.
+func (w *walker) child(e ...pathElem) *walker {
+	c := w.hasher.newWalker()
+
+	// Limit the capacity, so that appending copies instead of overwriting
+	// what w may append later.
+	c.path = append(w.path[:len(w.path):len(w.path)], e...)
+	c.stack = w.stack[:len(w.stack):len(w.stack)]
+	c.limits = w.limits
+	return c
+}
+
+func (h *Hasher) newWalker() *walker {
+	return &walker{
+		hasher: h,
+		format: h.format,
+		h:      h.newHash(),
+		opts:   &h.opts,
+	}
+}
+
+func (h *Hasher) newHash() hash.Hash {
+	if h.opts.NewHash != nil {
+		return h.opts.NewHash()
+	}
+
+	return h.format.newHash()
+}
+
+// structPlan is what a Hasher needs to know about a struct type to hash its
+// values.
+type structPlan struct {
+	// nameValue is the type name according to HashOptions.TypeNames, or
+	// the name set on a blank field, ready to be visited
+	nameValue reflect.Value
+
+	// optional is set for types of github.com/markphelps/optional
+	optional bool
+
+	// Interfaces implemented by the type and by a pointer to it
+	includable    bool
+	includableMap bool
+	hashable      bool
+	hashWriter    bool
+	ptrIncludable bool
+	ptrHashable   bool
+	ptrHashWriter bool
+
+	// fields are the fields to hash, in order. Unexported and ignored
+	// fields are left out.
+	fields []fieldPlan
+}
+
+type fieldPlan struct {
+	index int
+
+	// name is the name of the field in Go, which is used in paths and
+	// errors. nameValue is the name that is hashed, which can be set by
+	// the tag.
+	name      string
+	nameValue reflect.Value
+
+	// Tag directives
+	set       bool
+	str       bool
+	binary    bool
+	text      bool
+	omitEmpty bool
+
+	// stringer is set if the field type implements fmt.Stringer. If the
+	// field is an interface, this depends on the value and iface is set
+	// instead.
+	stringer bool
+	iface    bool
+}
+
+var (
+	includableType    = reflect.TypeOf((*Includable)(nil)).Elem()
+	includableMapType = reflect.TypeOf((*IncludableMap)(nil)).Elem()
+	hashableType      = reflect.TypeOf((*Hashable)(nil)).Elem()
+	hashWriterType    = reflect.TypeOf((*HashWriter)(nil)).Elem()
+	stringerType      = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+)
+
+// structPlan returns the plan for the struct type t, building it on first
+// use.
+func (h *Hasher) structPlan(t reflect.Type) *structPlan {
+	if p, ok := h.plans.Load(t); ok {
+		return p.(*structPlan)
+	}
+
+	ptr := reflect.PtrTo(t)
+	p := &structPlan{
+		nameValue:     reflect.ValueOf(h.typeName(t)),
+		optional:      t.PkgPath() == "github.com/markphelps/optional",
+		includable:    t.Implements(includableType),
+		includableMap: t.Implements(includableMapType),
+		hashable:      t.Implements(hashableType),
+		hashWriter:    t.Implements(hashWriterType),
+		ptrIncludable: ptr.Implements(includableType),
+		ptrHashable:   ptr.Implements(hashableType),
+		ptrHashWriter: ptr.Implements(hashWriterType),
+	}
+
+	l := t.NumField()
+	for i := 0; i < l; i++ {
+		fieldType := t.Field(i)
+
+		// Invalid tag values are ignored, Validate reports them
+		tag, _ := tag.Parse(fieldType.Tag.Get(h.tag))
+
+		if fieldType.Name == "_" {
+			// A blank field can set the type name
+			if tag.Name != "" && tag.NameOnly() && h.opts.TypeNames != TypeNameNone {
+				p.nameValue = reflect.ValueOf(tag.Name)
+			}
+			continue
+		}
+		if fieldType.PkgPath != "" {
+			// Unexported
+			continue
+		}
+		if tag.Ignore {
+			// Ignore this field
+			continue
+		}
+
+		name := fieldType.Name
+		if tag.Name != "" {
+			name = tag.Name
+		}
+
+		p.fields = append(p.fields, fieldPlan{
+			index:     i,
+			name:      fieldType.Name,
+			nameValue: reflect.ValueOf(name),
+			set:       tag.Set,
+			str:       tag.String,
+			binary:    tag.Binary,
+			text:      tag.Text,
+			omitEmpty: tag.OmitEmpty,
+			stringer:  fieldType.Type.Implements(stringerType),
+			iface:     fieldType.Type.Kind() == reflect.Interface,
+		})
+	}
+
+	// Another goroutine may have raced us here, in which case both plans
+	// are identical and it doesn't matter which one wins.
+	actual, _ := h.plans.LoadOrStore(t, p)
+	return actual.(*structPlan)
+}
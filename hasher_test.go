@@ -0,0 +1,192 @@
+package hashstructure
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestHasher(t *testing.T) {
+	for format := FormatMD5; format < formatMax; format++ {
+		t.Run(fmt.Sprintf("format_%d", format), func(t *testing.T) {
+			h, err := NewHasher(format, nil)
+			if err != nil {
+				t.Fatalf("error creating hasher: %v", err)
+			}
+
+			// Hash everything twice to exercise the cached plans
+			for i := 0; i < 2; i++ {
+				for _, v := range goldenStructs {
+					one, err := h.Hash(v)
+					if err != nil {
+						t.Fatalf("Failed to hash %#v: %s", v, err)
+					}
+					two, err := Hash(v, format, nil)
+					if err != nil {
+						t.Fatalf("Failed to hash %#v: %s", v, err)
+					}
+					if !bytes.Equal(one, two) {
+						t.Fatalf("non-matching: %d, %d\n\n%#v", one, two, v)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestHasher_copiesOptions(t *testing.T) {
+	v := struct {
+		Foo string
+		Bar string
+	}{Foo: "foo"}
+
+	opts := &HashOptions{}
+	h, err := NewHasher(FormatV2, opts)
+	if err != nil {
+		t.Fatalf("error creating hasher: %v", err)
+	}
+
+	one, err := h.Hash(v)
+	if err != nil {
+		t.Fatalf("Failed to hash %#v: %s", v, err)
+	}
+
+	opts.IgnoreZeroValue = true
+	two, err := h.Hash(v)
+	if err != nil {
+		t.Fatalf("Failed to hash %#v: %s", v, err)
+	}
+	if !bytes.Equal(one, two) {
+		t.Fatal("changing the options after NewHasher affected the hash")
+	}
+}
+
+func TestHasher_concurrent(t *testing.T) {
+	h, err := NewHasher(FormatV2, nil)
+	if err != nil {
+		t.Fatalf("error creating hasher: %v", err)
+	}
+
+	expected := make([][]byte, len(goldenStructs))
+	for i, v := range goldenStructs {
+		expected[i], err = Hash(v, FormatV2, nil)
+		if err != nil {
+			t.Fatalf("Failed to hash %#v: %s", v, err)
+		}
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 16)
+	for i := 0; i < cap(errs); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j, v := range goldenStructs {
+				got, err := h.Hash(v)
+				if err != nil {
+					errs <- err
+					return
+				}
+				if !bytes.Equal(got, expected[j]) {
+					errs <- fmt.Errorf("non-matching: %d, %d", got, expected[j])
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+func TestNewHasher_invalidFormat(t *testing.T) {
+	for _, format := range []Format{formatInvalid, formatMax} {
+		if _, err := NewHasher(format, nil); err == nil {
+			t.Fatalf("expected error for format %d", format)
+		}
+	}
+}
+
+type benchRecord struct {
+	ID       string `hash:"ignore"`
+	Name     string
+	Email    string
+	Age      int
+	Active   bool
+	Tags     []string `hash:"set"`
+	Balance  float64
+	Address  benchAddress
+	Metadata map[string]string
+}
+
+type benchAddress struct {
+	Street  string
+	City    string
+	Country string
+	Zip     string
+}
+
+var benchValue = benchRecord{
+	ID:      "2f1c7b0e",
+	Name:    "mitchellh",
+	Email:   "mitchellh@example.com",
+	Age:     64,
+	Active:  true,
+	Tags:    []string{"admin", "ops", "dev"},
+	Balance: 1234.56,
+	Address: benchAddress{
+		Street:  "1 Infinite Loop",
+		City:    "Cupertino",
+		Country: "US",
+		Zip:     "95014",
+	},
+	Metadata: map[string]string{
+		"car":      "true",
+		"location": "California",
+	},
+}
+
+func BenchmarkHash(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := Hash(benchValue, FormatV2, nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkHasher(b *testing.B) {
+	h, err := NewHasher(FormatV2, nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := h.Hash(benchValue); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkHasher_parallel(b *testing.B) {
+	h, err := NewHasher(FormatV2, nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := h.Hash(benchValue); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -3,11 +3,15 @@ package hashstructure
 import (
 	"bytes"
 	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
 	"encoding/binary"
 	"fmt"
 	"hash"
 	"reflect"
 	"sort"
+	"strings"
 	"time"
 )
 
@@ -26,15 +30,93 @@ type HashOptions struct {
 	IgnoreZeroValue bool
 
 	// SlicesAsSets assumes that a `set` tag is always present for slices.
-	// Default is false (in which case the tag is used instead)
+	// Default is false (in which case the tag is used instead). It only
+	// applies to FormatV2; the other formats hash slices in order.
 	SlicesAsSets bool
 
+	// Cycles determines what happens when a value refers back to itself
+	// through pointers, maps, slices or interfaces. By default this is
+	// CycleError.
+	Cycles CyclePolicy
+
+	// Unsupported determines what happens to funcs, channels and unsafe
+	// pointers, which can't be hashed by content. By default this is
+	// UnsupportedError.
+	Unsupported UnsupportedPolicy
+
+	// TypeNames determines how the names of struct types are hashed. By
+	// default this is TypeNameShort.
+	TypeNames TypeNamePolicy
+
 	// UseStringer will attempt to use fmt.Stringer always. If the struct
 	// doesn't implement fmt.Stringer, it'll fall back to trying usual tricks.
 	// If this is true, and the "string" tag is also set, the tag takes
 	// precedence (meaning that if the type doesn't implement fmt.Stringer, we
 	// panic)
 	UseStringer bool
+
+	// UseMarshaler hashes values that implement encoding.BinaryMarshaler,
+	// or else encoding.TextMarshaler, by their marshalled form instead of
+	// walking their fields. This generalizes how time.Time is always hashed.
+	// The "binary" and "text" tags do the same for a single field.
+	UseMarshaler bool
+
+	// NewHash, if set, is used to construct the hash.Hash that values are
+	// written to instead of the hasher selected by the Format. This lets
+	// callers pick their own trade-off between speed and collision
+	// resistance, for example with hash/fnv or hash/crc64. The function is
+	// called once per top-level value and once per element whenever an
+	// intermediate hash is required, such as for map entries and sets.
+	NewHash func() hash.Hash
+
+	// TypeHashers maps types to functions that write their canonical bytes,
+	// for types that can't implement Hashable themselves, such as types from
+	// other packages. A function registered for a type is used instead of
+	// the usual rules for that type, including for time.Time. Both pointer
+	// and non-pointer types can be registered, as well as interface types,
+	// which are matched by the declared type of struct fields, elements and
+	// map entries, before the type of the value they hold. Nil pointers and
+	// interfaces are never passed to a function.
+	TypeHashers map[reflect.Type]TypeHashFunc
+
+	// Merkle, if true, hashes every struct, slice, array and map, as well
+	// as every value behind a pointer, on its own, and writes only the
+	// resulting hash in its place. Hashes are different from those computed
+	// without it.
+	Merkle bool
+
+	// Cache, if set along with Merkle, remembers the hashes of values
+	// behind pointers, so that they aren't walked again. See Cache for
+	// when it needs to be invalidated. The cache isn't used with
+	// CycleBackRef, as back-references make the hash of a value depend on
+	// where it is found.
+	Cache *Cache
+
+	// Parallelism is the number of goroutines that may hash the entries
+	// of a map or the elements of a set at the same time. It is shared by
+	// all the maps and sets within a value, and by all the values hashed
+	// concurrently with the same Hasher. 0 or 1 hash sequentially. Hashes
+	// are the same either way.
+	//
+	// Types with Hashable, HashWriter or TypeHashers functions, as well as
+	// NewHash, must be safe for concurrent use when this is set.
+	Parallelism int
+
+	// ParallelThreshold is the number of entries or elements from which a
+	// map or a set is hashed in parallel. The default is 256.
+	ParallelThreshold int
+
+	// MaxDepth, MaxElements and MaxBytes limit the values that can be
+	// hashed, for values from untrusted sources. Hashing a value that
+	// exceeds a limit fails with an *ErrLimit. 0 means no limit.
+	//
+	// MaxDepth limits the number of fields, elements and map entries on
+	// the way to any value, MaxElements the total number of elements and
+	// entries of all arrays, slices and maps, and MaxBytes the total number
+	// of bytes written to hash.Hash instances, including intermediate ones.
+	MaxDepth    int
+	MaxElements int
+	MaxBytes    int
 }
 
 // Format specifies the hashing process used. Different formats typically
@@ -48,9 +130,81 @@ const (
 	// FormatMD5 uses the MD5 hasher.
 	FormatMD5
 
+	// FormatSHA1 uses the SHA-1 hasher.
+	FormatSHA1
+
+	// FormatSHA224 uses the SHA-224 hasher.
+	FormatSHA224
+
+	// FormatSHA256 uses the SHA-256 hasher.
+	FormatSHA256
+
+	// FormatSHA384 uses the SHA-384 hasher.
+	FormatSHA384
+
+	// FormatSHA512 uses the SHA-512 hasher.
+	FormatSHA512
+
+	// FormatV2 uses the SHA-256 hasher over a canonical encoding of the
+	// value. Every value is prefixed with a tag for its kind and, where
+	// needed, its length, so unlike the formats above different values never
+	// write the same bytes. The formats above are kept to reproduce
+	// previously stored hashes.
+	FormatV2
+
 	formatMax // so we can easily find the end
 )
 
+var formatNames = [...]string{
+	FormatMD5:    "md5",
+	FormatSHA1:   "sha1",
+	FormatSHA224: "sha224",
+	FormatSHA256: "sha256",
+	FormatSHA384: "sha384",
+	FormatSHA512: "sha512",
+	FormatV2:     "v2",
+}
+
+// String returns the name of the format, such as "sha256" or "v2", as
+// accepted by ParseFormat.
+func (f Format) String() string {
+	if f <= formatInvalid || f >= formatMax {
+		return fmt.Sprintf("Format(%d)", uint(f))
+	}
+
+	return formatNames[f]
+}
+
+// ParseFormat returns the format with the given name, as returned by
+// Format.String. Names are case-insensitive.
+func ParseFormat(name string) (Format, error) {
+	for f := FormatMD5; f < formatMax; f++ {
+		if strings.EqualFold(name, formatNames[f]) {
+			return f, nil
+		}
+	}
+
+	return formatInvalid, &ErrFormat{}
+}
+
+// newHash returns a new hash.Hash for the format. The format must be valid.
+func (f Format) newHash() hash.Hash {
+	switch f {
+	case FormatSHA1:
+		return sha1.New()
+	case FormatSHA224:
+		return sha256.New224()
+	case FormatSHA256, FormatV2:
+		return sha256.New()
+	case FormatSHA384:
+		return sha512.New384()
+	case FormatSHA512:
+		return sha512.New()
+	default:
+		return md5.New()
+	}
+}
+
 // Hash returns the hash value of an arbitrary value.
 //
 // If opts is nil, then default options will be used. See HashOptions
@@ -76,52 +230,78 @@ const (
 //	    UUID string `hash:"ignore"`
 //	}
 //
-// The available tag values are:
+// A tag is a comma-separated list of directives, such as
+// `hash:"set,omitempty"`. The available directives are:
 //
 //   - "ignore" or "-" - The field will be ignored and not affect the hash code.
+//     This can't be combined with other directives.
 //
 //   - "set" - The field will be treated as a set, where ordering doesn't
 //     affect the hash code. This only works for slices.
 //
 //   - "string" - The field will be hashed as a string, only works when the
 //     field implements fmt.Stringer
+//
+//   - "binary" or "text" - The field will be hashed by the output of
+//     MarshalBinary or MarshalText, only works when the field implements
+//     encoding.BinaryMarshaler or encoding.TextMarshaler respectively.
+//     Only one of "string", "binary" and "text" can be given.
+//
+//   - "omitempty" - The field will be left out when it holds the zero value
+//     of its type, as with HashOptions.IgnoreZeroValue.
+//
+//   - "name=..." - The field will be hashed under the given name instead of
+//     its name in Go, so that it can be renamed without changing the hash.
+//     No two fields of a struct should be hashed under the same name.
+//
+// Likewise, the name of the struct type that is hashed can be set with a
+// blank field, which can carry nothing but the name:
+//
+//	type Order struct {
+//	    _  struct{} `hash:"name=Purchase"`
+//	    ID string   `hash:"name=PurchaseID"`
+//	}
+//
+// Tags that can't be parsed, such as ones with unknown directives, are
+// ignored. Use Validate or the hashcheck analyzer to find them.
+//
+// Structs can take over their own hashing by implementing HashWriter or
+// Hashable.
 func Hash(v any, format Format, opts *HashOptions) ([]byte, error) {
-	// Validate our format
-	if format <= formatInvalid || format >= formatMax {
-		return nil, &ErrFormat{}
-	}
-
-	// Create default options
-	if opts == nil {
-		opts = &HashOptions{}
-	}
-
-	return hashValue(reflect.ValueOf(v), format, opts)
-}
-
-func hashValue(v reflect.Value, format Format, opts *HashOptions) ([]byte, error) {
-	tagName := opts.TagName
-	if tagName == "" {
-		tagName = "hash"
+	h, err := NewHasher(format, opts)
+	if err != nil {
+		return nil, err
 	}
 
-	// Create our walker and walk the structure
-	w := &walker{
-		format: format,
-		h:      md5.New(),
-		tag:    tagName,
-		opts:   opts,
-	}
-	err := w.visit(v, nil)
-	return w.h.Sum(nil), err
+	return h.Hash(v)
 }
 
 type walker struct {
+	hasher *Hasher
 	format Format
 	h      hash.Hash
-	tag    string
 
 	opts *HashOptions
+
+	// path is the location of the value being visited
+	path []pathElem
+
+	// stack holds the pointers, maps and slices being visited, to detect
+	// cycles
+	stack []ref
+
+	// tree is set when building a tree for HashTree. nodes then holds the
+	// nodes of the values being visited, innermost last.
+	tree  bool
+	nodes []*Node
+
+	// inline is set on walkers created by visitNode, whose first value must
+	// be visited rather than hashed as a node of its own again
+	inline bool
+
+	// limits is set when any of the limits in HashOptions is, or when
+	// hashing with a context
+	limits *limits
 }
 
 type visitCtx struct {
@@ -136,12 +316,42 @@ type visitCtx struct {
 var timeType = reflect.TypeOf(time.Time{})
 
 // visit visits a value recursively and updates w.h
-func (w *walker) visit(v reflect.Value, ctx *visitCtx) error {
-	t := reflect.TypeOf(0)
+func (w *walker) visit(v reflect.Value, ctx *visitCtx) (err error) {
+	// Errors are wrapped with the path of the innermost value that failed
+	defer func() {
+		if err != nil {
+			err = w.wrapErr(v, err)
+		}
+	}()
+
+	if w.limits != nil {
+		if err := w.checkVisit(); err != nil {
+			return err
+		}
+	}
+
+	// t is the type whose zero value stands in for a nil pointer, with
+	// ZeroNil
+	var t reflect.Type
+
+	// Anything pushed onto the stack here is popped when we return
+	var entered bool
+	depth := len(w.stack)
 
 	// Loop since these can be wrapped in multiple layers of pointers
 	// and interfaces.
 	for {
+		// Registered functions are looked up before dereferencing, so that
+		// functions for interface and pointer types are found
+		if v.IsValid() && len(w.opts.TypeHashers) > 0 {
+			fn, ok := w.opts.TypeHashers[v.Type()]
+			if ok && !((v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil()) {
+				return w.visitCustom(func(cw *Writer) error {
+					return fn(cw, v.Interface())
+				})
+			}
+		}
+
 		// If we have an interface, dereference it. We have to do this up
 		// here because it might be a nil in there and the check below must
 		// catch that.
@@ -154,6 +364,18 @@ func (w *walker) visit(v reflect.Value, ctx *visitCtx) error {
 			if w.opts.ZeroNil {
 				t = v.Type().Elem()
 			}
+			if !v.IsNil() {
+				if w.opts.Merkle && !w.inline {
+					return w.visitNode(v, ctx)
+				}
+				if ok, err := w.enter(v); !ok {
+					return err
+				}
+				if !entered {
+					entered = true
+					defer w.leave(depth)
+				}
+			}
 			v = reflect.Indirect(v)
 			continue
 		}
@@ -161,9 +383,26 @@ func (w *walker) visit(v reflect.Value, ctx *visitCtx) error {
 		break
 	}
 
-	// If it is nil, treat it like a zero.
+	// In Merkle mode, the value a walker starts with is visited in place
+	inline := w.inline
+	w.inline = false
+
+	if w.tree {
+		w.describe(v)
+	}
+
+	// If it is nil, treat it like a zero. Formats before FormatV2 hash nil
+	// like an int 0, while FormatV2 writes nil, so the two can't collide.
 	if !v.IsValid() {
+		if t == nil {
+			if w.format.canonical() {
+				return w.writeTag(tagNil)
+			}
+			t = reflect.TypeOf(0)
+		}
 		v = reflect.Zero(t)
+	} else if ok, err := w.visitMarshaler(v, ctx); ok {
+		return err
 	}
 
 	// Binary writing can use raw ints, we have to convert to
@@ -171,22 +410,29 @@ func (w *walker) visit(v reflect.Value, ctx *visitCtx) error {
 	switch v.Kind() {
 	case reflect.Int:
 		v = reflect.ValueOf(int64(v.Int()))
-	case reflect.Uint:
+	case reflect.Uint, reflect.Uintptr:
 		v = reflect.ValueOf(uint64(v.Uint()))
 	case reflect.Bool:
 		var tmp int8
 		if v.Bool() {
 			tmp = 1
 		}
-		v = reflect.ValueOf(tmp)
+		if err := w.writeTag(tagBool); err != nil {
+			return err
+		}
+		return binary.Write(w, binary.LittleEndian, tmp)
 	}
 
 	k := v.Kind()
 
 	// We can shortcut numeric values by directly binary writing them
-	if k >= reflect.Int && k <= reflect.Complex64 {
+	if k >= reflect.Int && k <= reflect.Complex128 {
+		if err := w.writeTag(numberTag(k)); err != nil {
+			return err
+		}
+
 		// A direct hash calculation
-		return binary.Write(w.h, binary.LittleEndian, v.Interface())
+		return binary.Write(w, binary.LittleEndian, v.Interface())
 	}
 
 	switch v.Type() {
@@ -196,15 +442,43 @@ func (w *walker) visit(v reflect.Value, ctx *visitCtx) error {
 			return err
 		}
 
-		err = binary.Write(w.h, binary.LittleEndian, b)
+		if err := w.writeHeader(tagTime, len(b)); err != nil {
+			return err
+		}
+		err = binary.Write(w, binary.LittleEndian, b)
 		return err
 	}
 
+	if w.opts.Merkle && !inline && isNode(k) {
+		return w.visitNode(v, ctx)
+	}
+
+	if w.limits != nil && (k == reflect.Array || k == reflect.Slice || k == reflect.Map) {
+		if err := w.checkElements(v.Len()); err != nil {
+			return err
+		}
+	}
+
+	// Maps and slices can contain themselves through interfaces
+	if (k == reflect.Map || k == reflect.Slice) && v.Len() > 0 {
+		if ok, err := w.enter(v); !ok {
+			return err
+		}
+		if !entered {
+			defer w.leave(depth)
+		}
+	}
+
 	switch k {
 	case reflect.Array:
 		l := v.Len()
+		if err := w.writeHeader(tagArray, l); err != nil {
+			return err
+		}
 		for i := 0; i < l; i++ {
+			w.push(indexElem(i))
 			err := w.visit(v.Index(i), nil)
+			w.pop()
 			if err != nil {
 				return err
 			}
@@ -221,10 +495,17 @@ func (w *walker) visit(v reflect.Value, ctx *visitCtx) error {
 		return w.visitSlice(v, ctx)
 
 	case reflect.String:
+		if err := w.writeHeader(tagString, v.Len()); err != nil {
+			return err
+		}
+
 		// Directly hash
-		_, err := w.h.Write([]byte(v.String()))
+		_, err := w.Write([]byte(v.String()))
 		return err
 
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return w.visitUnsupported(v)
+
 	default:
 		return fmt.Errorf("unknown kind to hash: %s", k)
 	}
@@ -243,8 +524,12 @@ func (w *walker) visitMap(v reflect.Value, opts *visitCtx) error {
 	// and values. Then we sort the hashes, and finally, write the hashes
 	// in order to w.h to update the overall hash.
 	// This makes for a deterministic hash regardless of map traversal order.
-	keyHashes := make([][]byte, 0, v.Len())
-	valueHashes := make([][]byte, 0, v.Len())
+	//
+	// Formats before FormatV2 sort the key and value hashes independently,
+	// which loses which value belongs to which key. FormatV2 hashes each
+	// entry as a whole instead.
+	keys := make([]reflect.Value, 0, v.Len())
+	values := make([]reflect.Value, 0, v.Len())
 	for _, k := range v.MapKeys() {
 		v := v.MapIndex(k)
 		if includeMap != nil {
@@ -258,16 +543,50 @@ func (w *walker) visitMap(v reflect.Value, opts *visitCtx) error {
 			}
 		}
 
-		kHash, err := hashValue(k, w.format, w.opts)
-		if err != nil {
+		keys = append(keys, k)
+		values = append(values, v)
+	}
+
+	entryHashes := make([][]byte, len(keys))
+	keyHashes := make([][]byte, len(keys))
+	valueHashes := make([][]byte, len(keys))
+	err := w.forEach(len(keys), func(i int) error {
+		k, v := keys[i], values[i]
+		if w.format.canonical() {
+			h, err := w.hashEntry(k, v)
+			entryHashes[i] = h
 			return err
 		}
-		vHash, err := hashValue(v, w.format, w.opts)
-		if err != nil {
+
+		// The key is hashed without w.hashValue, so that only the value
+		// gets a node in the tree for HashTree
+		kw := w.child(keyElem(k))
+		if err := kw.visit(k, nil); err != nil {
+			return err
+		}
+		keyHashes[i] = kw.h.Sum(nil)
+
+		vHash, err := w.hashValue(v, keyElem(k))
+		valueHashes[i] = vHash
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if w.format.canonical() {
+		sort.Slice(entryHashes, func(i, j int) bool {
+			return bytes.Compare(entryHashes[i], entryHashes[j]) < 0
+		})
+		if err := w.writeHeader(tagMap, len(entryHashes)); err != nil {
 			return err
 		}
-		keyHashes = append(keyHashes, kHash)
-		valueHashes = append(valueHashes, vHash)
+		for _, h := range entryHashes {
+			if _, err := w.Write(h); err != nil {
+				return err
+			}
+		}
+		return nil
 	}
 
 	sort.Slice(keyHashes, func(i, j int) bool {
@@ -277,159 +596,221 @@ func (w *walker) visitMap(v reflect.Value, opts *visitCtx) error {
 		return bytes.Compare(valueHashes[i], valueHashes[j]) < 0
 	})
 	for _, h := range keyHashes {
-		w.h.Write(h)
+		if _, err := w.Write(h); err != nil {
+			return err
+		}
 	}
 	for _, h := range valueHashes {
-		w.h.Write(h)
+		if _, err := w.Write(h); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
 func (w *walker) visitStruct(v reflect.Value) error {
-	parent := v.Interface()
+	t := v.Type()
+	plan := w.hasher.structPlan(t)
+
+	// Only box the value when one of our interfaces needs it
+	var parent any
 	var include Includable
-	if impl, ok := parent.(Includable); ok {
-		include = impl
+	if plan.includable || plan.includableMap || plan.hashable || plan.hashWriter {
+		parent = v.Interface()
+	}
+	if plan.includable {
+		include = parent.(Includable)
 	}
 
-	if impl, ok := parent.(Hashable); ok {
-		h, err := impl.Hash()
+	if plan.hashWriter {
+		return w.visitCustom(parent.(HashWriter).HashTo)
+	}
+
+	if plan.hashable {
+		h, err := parent.(Hashable).Hash()
 		if err != nil {
 			return err
 		}
-		_, err = fmt.Fprintf(w.h, "%d", h)
-		return err
+		return w.writeHashable(h)
 	}
 
 	// If we can address this value, check if the pointer value
 	// implements our interfaces and use that if so.
-	if v.CanAddr() {
-		vptr := v.Addr()
-		parentptr := vptr.Interface()
-		if impl, ok := parentptr.(Includable); ok {
-			include = impl
+	if v.CanAddr() && (plan.ptrIncludable || plan.ptrHashable || plan.ptrHashWriter) {
+		parentptr := v.Addr().Interface()
+		if plan.ptrIncludable {
+			include = parentptr.(Includable)
 		}
 
-		if impl, ok := parentptr.(Hashable); ok {
-			h, err := impl.Hash()
+		if plan.ptrHashWriter {
+			return w.visitCustom(parentptr.(HashWriter).HashTo)
+		}
+
+		if plan.ptrHashable {
+			h, err := parentptr.(Hashable).Hash()
 			if err != nil {
 				return err
 			}
-			_, err = w.h.Write([]byte(fmt.Sprintf("%d", h)))
-			return err
+			return w.writeHashable(h)
 		}
 	}
 
-	t := v.Type()
-
 	// we need to "unbox" the value in an optional struct
 	// becuase the actual value is a private field
-	if t.PkgPath() == "github.com/markphelps/optional" {
+	if plan.optional {
+		if w.format.canonical() {
+			return w.visitOptionalCanonical(v)
+		}
 		return w.visitOptional(v, t.Name())
 	}
 
-	err := w.visit(reflect.ValueOf(t.Name()), nil)
+	if err := w.writeTag(tagStruct); err != nil {
+		return err
+	}
+
+	err := w.visit(plan.nameValue, nil)
 	if err != nil {
 		return err
 	}
 
-	l := v.NumField()
-	for i := 0; i < l; i++ {
-		if innerV := v.Field(i); v.CanSet() || t.Field(i).Name != "_" {
-			var f visitFlag
-			fieldType := t.Field(i)
-			if fieldType.PkgPath != "" {
-				// Unexported
-				continue
-			}
+	for i := range plan.fields {
+		field := &plan.fields[i]
+		innerV := v.Field(field.index)
 
-			tag := fieldType.Tag.Get(w.tag)
-			if tag == "ignore" || tag == "-" {
-				// Ignore this field
+		if w.opts.IgnoreZeroValue || field.omitEmpty {
+			if innerV.IsZero() {
 				continue
 			}
+		}
 
-			if w.opts.IgnoreZeroValue {
-				if innerV.IsZero() {
-					continue
-				}
-			}
+		if w.opts.Unsupported == UnsupportedSkip && unsupported(innerV) {
+			continue
+		}
 
-			// if string is set, use the string value
-			if tag == "string" || w.opts.UseStringer {
-				if impl, ok := innerV.Interface().(fmt.Stringer); ok {
-					innerV = reflect.ValueOf(impl.String())
-				} else if tag == "string" {
-					// We only show this error if the tag explicitly
-					// requests a stringer.
-					return &ErrNotStringer{
-						Field: v.Type().Field(i).Name,
-					}
-				}
+		// if string is set, use the string value
+		if field.str || w.opts.UseStringer {
+			var impl fmt.Stringer
+			var ok bool
+			if field.stringer || field.iface {
+				impl, ok = innerV.Interface().(fmt.Stringer)
 			}
-
-			// Check if we implement includable and check it
-			if include != nil {
-				incl, err := include.HashInclude(fieldType.Name, innerV)
-				if err != nil {
-					return err
-				}
-				if !incl {
-					continue
-				}
-			}
-
-			switch tag {
-			case "set":
-				f |= visitFlagSet
+			if ok {
+				innerV = reflect.ValueOf(impl.String())
+			} else if field.str {
+				// We only show this error if the tag explicitly
+				// requests a stringer.
+				return w.fieldError(field.name, innerV, &ErrNotStringer{
+					Field: field.name,
+				})
 			}
+		}
 
-			err := w.visit(reflect.ValueOf(fieldType.Name), nil)
+		// Check if we implement includable and check it
+		if include != nil {
+			incl, err := include.HashInclude(field.name, innerV)
 			if err != nil {
-				return err
+				return w.fieldError(field.name, innerV, err)
 			}
-
-			err = w.visit(innerV, &visitCtx{
-				Flags:       f,
-				Struct:      parent,
-				StructField: fieldType.Name,
-			})
-			if err != nil {
-				return err
+			if !incl {
+				continue
 			}
+		}
 
+		var f visitFlag
+		if field.set {
+			f |= visitFlagSet
+		}
+		if field.binary {
+			f |= visitFlagBinary
+		}
+		if field.text {
+			f |= visitFlagText
+		}
+
+		err := w.visit(field.nameValue, nil)
+		if err != nil {
+			return err
 		}
 
+		w.push(fieldElem(field.name))
+		err = w.visit(innerV, &visitCtx{
+			Flags:       f,
+			Struct:      parent,
+			StructField: field.name,
+		})
+		w.pop()
+		if err != nil {
+			return err
+		}
 	}
 
-	return nil
+	return w.writeTag(tagEnd)
 }
 
 func (w *walker) visitSlice(v reflect.Value, ctx *visitCtx) error {
 	// We have two behaviors here. If it isn't a set, then we just
 	// visit all the elements. If it is a set, then we do a deterministic
-	// hash code.
-	var set bool
-	if ctx != nil {
-		set = (ctx.Flags & visitFlagSet) != 0
+	// hash code. SlicesAsSets only applies to canonical formats, as the
+	// legacy set hashing below can't tell slices apart.
+	set := w.opts.SlicesAsSets && w.format.canonical()
+	if ctx != nil && (ctx.Flags&visitFlagSet) != 0 {
+		set = true
 	}
 	l := v.Len()
 	if !set {
+		if err := w.writeHeader(tagSlice, l); err != nil {
+			return err
+		}
+
 		// Visit each index in order
 		for i := 0; i < l; i++ {
-			if err := w.visit(v.Index(i), nil); err != nil {
+			w.push(indexElem(i))
+			err := w.visit(v.Index(i), nil)
+			w.pop()
+			if err != nil {
+				return err
+			}
+		}
+	} else if w.format.canonical() {
+		if w.tree {
+			w.nodes[len(w.nodes)-1].unordered = true
+		}
+
+		// Same as below, with the element hashes written as-is.
+		hashes := make([][]byte, l)
+		err := w.forEach(l, func(i int) error {
+			h, err := w.hashValue(v.Index(i), indexElem(i))
+			hashes[i] = h
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		sort.Slice(hashes, func(i, j int) bool {
+			return bytes.Compare(hashes[i], hashes[j]) < 0
+		})
+		if err := w.writeHeader(tagSet, len(hashes)); err != nil {
+			return err
+		}
+		for _, h := range hashes {
+			if _, err := w.Write(h); err != nil {
 				return err
 			}
 		}
 	} else {
+		// NOTE: the error check below is inverted, so this returns as soon
+		// as an element hashes successfully, without writing anything. It
+		// is kept as-is because formats before FormatV2 must keep
+		// producing the same hashes.
+
 		// Build hash for slice treated as set (unordered)
 		// First, hash each element, then sort the hashes
 		// and write them sequentially to w.h to update the overall hash.
 		// This leads to a deterministic hash for the slice regardless of element ordering.
 		hashes := make([][]byte, 0, l)
 		for i := 0; i < l; i++ {
-			if h, err := hashValue(v.Index(i), w.format, w.opts); err != nil {
+			if h, err := w.hashValue(v.Index(i), indexElem(i)); err != nil {
 				hashes = append(hashes, h)
 			} else {
 				return err
@@ -439,7 +820,9 @@ func (w *walker) visitSlice(v reflect.Value, ctx *visitCtx) error {
 			return bytes.Compare(hashes[i], hashes[j]) < 0
 		})
 		for _, h := range hashes {
-			fmt.Fprintf(w.h, "%d", h)
+			if _, err := fmt.Fprintf(w, "%d", h); err != nil {
+				return err
+			}
 		}
 	}
 
@@ -452,4 +835,6 @@ type visitFlag uint
 const (
 	visitFlagInvalid visitFlag = iota
 	visitFlagSet               = iota << 1
+	visitFlagBinary            = 1 << 2
+	visitFlagText              = 1 << 3
 )
@@ -82,24 +82,69 @@ var (
 	}
 
 	goldenStructs = []goldenStruct{goldenStructA, goldenStructB, goldenStructC, goldenStructD}
-	goldenHashes  = [][]byte{
-		{115, 205, 154, 57, 182, 201, 82, 233, 17, 152, 239, 179, 145, 124, 147, 33},
-		{115, 205, 154, 57, 182, 201, 82, 233, 17, 152, 239, 179, 145, 124, 147, 33},
-		{54, 216, 215, 148, 238, 156, 123, 242, 153, 51, 225, 50, 219, 99, 82, 194},
-		{105, 71, 150, 88, 101, 21, 32, 136, 53, 28, 235, 133, 95, 114, 36, 110},
+	goldenHashes  = map[Format][][]byte{
+		FormatMD5: {
+			{115, 205, 154, 57, 182, 201, 82, 233, 17, 152, 239, 179, 145, 124, 147, 33},
+			{115, 205, 154, 57, 182, 201, 82, 233, 17, 152, 239, 179, 145, 124, 147, 33},
+			{54, 216, 215, 148, 238, 156, 123, 242, 153, 51, 225, 50, 219, 99, 82, 194},
+			{105, 71, 150, 88, 101, 21, 32, 136, 53, 28, 235, 133, 95, 114, 36, 110},
+		},
+		FormatSHA1: {
+			{67, 252, 127, 96, 21, 5, 152, 236, 157, 18, 41, 67, 224, 0, 104, 13, 137, 197, 25, 42},
+			{67, 252, 127, 96, 21, 5, 152, 236, 157, 18, 41, 67, 224, 0, 104, 13, 137, 197, 25, 42},
+			{124, 29, 40, 130, 207, 167, 105, 215, 207, 130, 72, 143, 170, 52, 205, 150, 251, 229, 195, 169},
+			{140, 241, 150, 201, 69, 196, 76, 9, 59, 225, 66, 236, 113, 141, 105, 39, 229, 4, 231, 94},
+		},
+		FormatSHA224: {
+			{195, 113, 161, 40, 199, 75, 197, 162, 157, 210, 94, 252, 34, 12, 137, 38, 105, 60, 172, 217, 233, 75, 51, 209, 213, 181, 142, 208},
+			{195, 113, 161, 40, 199, 75, 197, 162, 157, 210, 94, 252, 34, 12, 137, 38, 105, 60, 172, 217, 233, 75, 51, 209, 213, 181, 142, 208},
+			{21, 157, 88, 100, 71, 235, 50, 2, 78, 255, 211, 162, 230, 104, 206, 135, 82, 202, 206, 154, 193, 192, 77, 201, 190, 146, 111, 12},
+			{126, 121, 22, 38, 175, 237, 127, 220, 15, 111, 131, 122, 19, 205, 243, 75, 38, 42, 183, 236, 83, 12, 50, 87, 187, 206, 181, 143},
+		},
+		FormatSHA256: {
+			{123, 35, 195, 110, 19, 9, 7, 161, 237, 67, 228, 8, 149, 89, 99, 26, 29, 54, 78, 222, 107, 141, 145, 162, 182, 88, 103, 230, 93, 63, 234, 47},
+			{123, 35, 195, 110, 19, 9, 7, 161, 237, 67, 228, 8, 149, 89, 99, 26, 29, 54, 78, 222, 107, 141, 145, 162, 182, 88, 103, 230, 93, 63, 234, 47},
+			{103, 160, 254, 18, 29, 138, 5, 143, 119, 129, 203, 32, 101, 125, 202, 51, 59, 30, 72, 39, 185, 234, 101, 228, 121, 152, 152, 183, 164, 7, 48, 190},
+			{59, 36, 149, 14, 153, 214, 155, 135, 41, 231, 25, 158, 79, 153, 91, 90, 148, 210, 193, 4, 37, 162, 160, 82, 1, 16, 33, 193, 48, 191, 231, 39},
+		},
+		FormatSHA384: {
+			{223, 71, 198, 60, 123, 146, 34, 24, 190, 38, 5, 243, 39, 14, 102, 249, 136, 10, 208, 73, 187, 138, 100, 64, 10, 159, 248, 233, 103, 111, 162, 88, 171, 194, 27, 227, 183, 166, 110, 50, 47, 74, 244, 118, 136, 220, 105, 250},
+			{223, 71, 198, 60, 123, 146, 34, 24, 190, 38, 5, 243, 39, 14, 102, 249, 136, 10, 208, 73, 187, 138, 100, 64, 10, 159, 248, 233, 103, 111, 162, 88, 171, 194, 27, 227, 183, 166, 110, 50, 47, 74, 244, 118, 136, 220, 105, 250},
+			{188, 58, 102, 97, 41, 143, 100, 248, 135, 164, 188, 61, 182, 162, 18, 245, 228, 159, 81, 139, 230, 21, 59, 144, 13, 251, 114, 220, 37, 21, 77, 62, 55, 124, 26, 20, 194, 16, 120, 217, 245, 98, 3, 192, 109, 43, 87, 15},
+			{99, 104, 200, 96, 91, 102, 68, 1, 12, 3, 171, 232, 60, 108, 131, 71, 150, 185, 130, 160, 177, 173, 113, 176, 37, 248, 53, 70, 242, 174, 230, 113, 136, 72, 205, 111, 83, 108, 81, 165, 122, 182, 108, 161, 45, 46, 124, 45},
+		},
+		FormatSHA512: {
+			{76, 99, 60, 112, 1, 0, 145, 15, 228, 169, 66, 188, 35, 170, 235, 29, 202, 19, 46, 218, 164, 33, 73, 74, 137, 29, 80, 84, 171, 58, 253, 212, 139, 9, 238, 204, 113, 114, 45, 40, 247, 104, 43, 143, 176, 157, 190, 69, 1, 46, 66, 12, 221, 75, 144, 133, 240, 253, 116, 62, 106, 24, 195, 47},
+			{76, 99, 60, 112, 1, 0, 145, 15, 228, 169, 66, 188, 35, 170, 235, 29, 202, 19, 46, 218, 164, 33, 73, 74, 137, 29, 80, 84, 171, 58, 253, 212, 139, 9, 238, 204, 113, 114, 45, 40, 247, 104, 43, 143, 176, 157, 190, 69, 1, 46, 66, 12, 221, 75, 144, 133, 240, 253, 116, 62, 106, 24, 195, 47},
+			{246, 76, 243, 205, 159, 164, 180, 21, 225, 161, 240, 124, 238, 244, 184, 181, 123, 39, 22, 66, 103, 53, 36, 204, 65, 145, 174, 219, 155, 30, 100, 196, 121, 87, 54, 228, 226, 175, 86, 251, 190, 43, 172, 227, 211, 77, 251, 43, 35, 66, 180, 68, 42, 184, 113, 241, 142, 181, 237, 208, 123, 7, 204, 241},
+			{160, 195, 152, 219, 239, 119, 194, 3, 244, 229, 237, 159, 70, 237, 237, 210, 14, 100, 131, 79, 60, 117, 183, 217, 134, 139, 108, 29, 134, 116, 101, 12, 160, 248, 171, 162, 216, 167, 42, 94, 136, 254, 219, 150, 170, 204, 242, 77, 134, 40, 225, 18, 184, 97, 223, 17, 100, 109, 64, 8, 162, 97, 167, 7},
+		},
+		FormatV2: {
+			{176, 137, 81, 200, 176, 99, 111, 94, 136, 102, 97, 46, 15, 116, 233, 101, 146, 116, 100, 178, 91, 174, 133, 167, 47, 252, 102, 9, 142, 231, 151, 29},
+			{176, 137, 81, 200, 176, 99, 111, 94, 136, 102, 97, 46, 15, 116, 233, 101, 146, 116, 100, 178, 91, 174, 133, 167, 47, 252, 102, 9, 142, 231, 151, 29},
+			{51, 76, 28, 86, 138, 39, 2, 33, 240, 194, 121, 254, 145, 234, 227, 77, 52, 28, 192, 93, 10, 15, 211, 199, 142, 83, 141, 151, 103, 24, 30, 194},
+			{199, 126, 207, 136, 7, 228, 83, 26, 123, 157, 53, 154, 63, 50, 33, 105, 209, 51, 73, 51, 244, 188, 140, 156, 120, 106, 116, 45, 234, 49, 22, 43},
+		},
 	}
 )
 
 func TestGoldenStructHashes(t *testing.T) {
-	for i := range goldenStructs {
-		t.Run(fmt.Sprintf("goldenStruct_%d", i), func(t *testing.T) {
-			h, err := Hash(goldenStructs[i], FormatMD5, nil)
-			if err != nil {
-				t.Errorf("error hashing: %v", err)
-			}
-			if !bytes.Equal(h, goldenHashes[i]) {
-				t.Errorf("incorrect hash %v", h)
-			}
-		})
+	for format := FormatMD5; format < formatMax; format++ {
+		hashes, ok := goldenHashes[format]
+		if !ok {
+			t.Fatalf("no golden hashes for format %d", format)
+		}
+
+		for i := range goldenStructs {
+			t.Run(fmt.Sprintf("format_%d/goldenStruct_%d", format, i), func(t *testing.T) {
+				h, err := Hash(goldenStructs[i], format, nil)
+				if err != nil {
+					t.Errorf("error hashing: %v", err)
+				}
+				if !bytes.Equal(h, hashes[i]) {
+					t.Errorf("incorrect hash %v", h)
+				}
+			})
+		}
 	}
 }
@@ -2,7 +2,11 @@ package hashstructure
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"hash"
+	"hash/fnv"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -329,7 +333,8 @@ func TestHash_stringTagError(t *testing.T) {
 	for _, tc := range cases {
 		_, err := Hash(tc.Test, testFormat, nil)
 		if err != nil {
-			if ens, ok := err.(*ErrNotStringer); ok {
+			var ens *ErrNotStringer
+			if errors.As(err, &ens) {
 				if ens.Field != tc.Field {
 					t.Fatalf("did not get expected field %#v: got %s wanted %s", tc.Test, ens.Field, tc.Field)
 				}
@@ -466,6 +471,45 @@ func TestHash_equalSet(t *testing.T) {
 	}
 }
 
+func TestHash_slicesAsSets(t *testing.T) {
+	type Test struct {
+		Name    string
+		Friends []string
+		Groups  [][]int
+	}
+
+	one := Test{Name: "foo", Friends: []string{"foo", "bar"}, Groups: [][]int{{1, 2}, {3}}}
+	two := Test{Name: "foo", Friends: []string{"bar", "foo"}, Groups: [][]int{{3}, {2, 1}}}
+	other := Test{Name: "foo", Friends: []string{"foo", "baz"}, Groups: [][]int{{1, 2}, {3}}}
+
+	for format := FormatMD5; format < formatMax; format++ {
+		for _, setOpt := range []bool{true, false} {
+			opts := &HashOptions{SlicesAsSets: setOpt}
+			hashOne, err := Hash(one, format, opts)
+			if err != nil {
+				t.Fatalf("Failed to hash %#v: %s", one, err)
+			}
+			hashTwo, err := Hash(two, format, opts)
+			if err != nil {
+				t.Fatalf("Failed to hash %#v: %s", two, err)
+			}
+			hashOther, err := Hash(other, format, opts)
+			if err != nil {
+				t.Fatalf("Failed to hash %#v: %s", other, err)
+			}
+
+			// Only FormatV2 hashes slices as sets.
+			match := setOpt && format == FormatV2
+			if bytes.Equal(hashOne, hashTwo) != match {
+				t.Fatalf("%s: SlicesAsSets %v: expected match %v", format, setOpt, match)
+			}
+			if bytes.Equal(hashOne, hashOther) {
+				t.Fatalf("%s: SlicesAsSets %v: slices with different elements should not match", format, setOpt)
+			}
+		}
+	}
+}
+
 func TestHash_includable(t *testing.T) {
 	cases := []struct {
 		One, Two interface{}
@@ -556,6 +600,122 @@ func TestHash_ignoreZeroValue(t *testing.T) {
 	}
 }
 
+func TestHash_tagDirectives(t *testing.T) {
+	type Omit struct {
+		Foo  string
+		Tags []string  `hash:"set,omitempty"`
+		Time time.Time `hash:"omitempty,string"`
+	}
+	// Without has the same name as Omit, without the omitted fields
+	without := func() interface{} {
+		type Omit struct {
+			Foo string
+		}
+		return Omit{Foo: "foo"}
+	}()
+	type Invalid struct {
+		Foo  string
+		Tags []string `hash:"set,sett"`
+	}
+
+	now := time.Now()
+	cases := []struct {
+		One, Two interface{}
+		Match    bool
+	}{
+		{
+			Omit{Foo: "foo"},
+			without,
+			true,
+		},
+		{
+			Omit{Foo: "foo", Tags: []string{"a", "b"}},
+			Omit{Foo: "foo", Tags: []string{"b", "a"}},
+			true,
+		},
+		{
+			Omit{Foo: "foo", Tags: []string{"a"}},
+			without,
+			false,
+		},
+		{
+			Omit{Foo: "foo", Time: now},
+			Omit{Foo: "foo", Time: now.Round(time.Hour)},
+			false,
+		},
+		{
+			// Invalid tags are ignored, so this isn't a set
+			Invalid{Foo: "foo", Tags: []string{"a", "b"}},
+			Invalid{Foo: "foo", Tags: []string{"b", "a"}},
+			false,
+		},
+	}
+
+	for _, tc := range cases {
+		one, err := Hash(tc.One, testFormat, nil)
+		if err != nil {
+			t.Fatalf("Failed to hash %#v: %s", tc.One, err)
+		}
+		two, err := Hash(tc.Two, testFormat, nil)
+		if err != nil {
+			t.Fatalf("Failed to hash %#v: %s", tc.Two, err)
+		}
+
+		if (bytes.Equal(one, two)) != tc.Match {
+			t.Fatalf("bad, expected: %#v\n\n%#v\n\n%#v", tc.Match, tc.One, tc.Two)
+		}
+	}
+}
+
+func TestHash_tagNames(t *testing.T) {
+	// Each version declares its own types, as a refactoring would
+	v1 := func() interface{} {
+		type Item struct {
+			ID   string
+			Tags []string `hash:"set"`
+		}
+		return Item{ID: "a", Tags: []string{"x", "y"}}
+	}
+	v2 := func() interface{} {
+		type Entry struct {
+			_ struct{} `hash:"name=Item"`
+
+			Key    string   `hash:"name=ID"`
+			Labels []string `hash:"name=Tags,set"`
+		}
+		return Entry{Key: "a", Labels: []string{"y", "x"}}
+	}
+	v3 := func() interface{} {
+		type Entry struct {
+			Key    string   `hash:"name=ID"`
+			Labels []string `hash:"name=Tags,set"`
+		}
+		return Entry{Key: "a", Labels: []string{"y", "x"}}
+	}
+
+	for _, format := range []Format{FormatV2, FormatSHA256} {
+		one, err := Hash(v1(), format, nil)
+		if err != nil {
+			t.Fatalf("Failed to hash: %s", err)
+		}
+		two, err := Hash(v2(), format, nil)
+		if err != nil {
+			t.Fatalf("Failed to hash: %s", err)
+		}
+		three, err := Hash(v3(), format, nil)
+		if err != nil {
+			t.Fatalf("Failed to hash: %s", err)
+		}
+
+		if !bytes.Equal(one, two) {
+			t.Fatalf("%s: renamed type and fields should hash the same", format)
+		}
+		if bytes.Equal(one, three) {
+			t.Fatalf("%s: renamed type should hash differently", format)
+		}
+	}
+}
+
 func TestHash_includableMap(t *testing.T) {
 	cases := []struct {
 		One, Two interface{}
@@ -677,6 +837,202 @@ func TestHash_hashable(t *testing.T) {
 	}
 }
 
+func TestHash_newHash(t *testing.T) {
+	v := map[string]interface{}{
+		"foo": []string{"bar", "baz"},
+		"bar": 42,
+	}
+
+	var calls int
+	opts := &HashOptions{
+		NewHash: func() hash.Hash {
+			calls++
+			return fnv.New128a()
+		},
+	}
+
+	one, err := Hash(v, testFormat, opts)
+	if err != nil {
+		t.Fatalf("Failed to hash %#v: %s", v, err)
+	}
+	if len(one) != 16 {
+		t.Fatalf("expected a 16 byte FNV-128 digest, got %d bytes", len(one))
+	}
+
+	// One call for the top-level value, and one for each key and value
+	if calls != 5 {
+		t.Fatalf("expected NewHash to be called 5 times, got %d", calls)
+	}
+
+	two, err := Hash(v, testFormat, opts)
+	if err != nil {
+		t.Fatalf("Failed to hash %#v: %s", v, err)
+	}
+	if !bytes.Equal(one, two) {
+		t.Fatalf("non-matching: %d, %d", one, two)
+	}
+
+	// The format's own hasher must not be used
+	formatHash, err := Hash(v, testFormat, nil)
+	if err != nil {
+		t.Fatalf("Failed to hash %#v: %s", v, err)
+	}
+	if bytes.Equal(one, formatHash) {
+		t.Fatalf("NewHash was ignored: %d", one)
+	}
+}
+
+func TestHash_errorPath(t *testing.T) {
+	type Item struct {
+		Price interface{}
+	}
+
+	type Order struct {
+		Items map[string]Item
+	}
+
+	type Test struct {
+		Orders []Order
+	}
+
+	v := Test{
+		Orders: []Order{
+			{}, {}, {},
+			{Items: map[string]Item{"sku": {Price: func() {}}}},
+		},
+	}
+
+	for _, format := range []Format{FormatMD5, FormatV2} {
+		_, err := Hash(v, format, nil)
+
+		var herr *HashError
+		if !errors.As(err, &herr) {
+			t.Fatalf("expected HashError, got: %v", err)
+		}
+		if herr.Path != `Orders[3].Items["sku"].Price` {
+			t.Fatalf("bad path: %s", herr.Path)
+		}
+		if herr.Kind != reflect.Func {
+			t.Fatalf("bad kind: %s", herr.Kind)
+		}
+		if !strings.Contains(err.Error(), `unknown kind to hash: func (at Orders[3].Items["sku"].Price)`) {
+			t.Fatalf("bad error: %s", err)
+		}
+	}
+
+	// Errors from Hashable are wrapped too
+	_, err := Hash(struct{ Inner testHashable }{testHashable{Err: errors.New("oh no")}}, testFormat, nil)
+	var herr *HashError
+	if !errors.As(err, &herr) {
+		t.Fatalf("expected HashError, got: %v", err)
+	}
+	if herr.Path != "Inner" || herr.Err.Error() != "oh no" {
+		t.Fatalf("bad error: %#v", herr)
+	}
+}
+
+func TestHash_unsupported(t *testing.T) {
+	type Test struct {
+		Name   string
+		OnSave func()
+		Events chan int
+		Hook   interface{}
+	}
+
+	cases := []struct {
+		One, Two interface{}
+		Policy   UnsupportedPolicy
+		Match    bool
+	}{
+		{
+			Test{Name: "foo", OnSave: func() {}, Events: make(chan int), Hook: func() {}},
+			Test{Name: "foo"},
+			UnsupportedNilness,
+			false,
+		},
+		{
+			Test{Name: "foo", OnSave: func() {}},
+			Test{Name: "foo", OnSave: func() {}},
+			UnsupportedNilness,
+			true,
+		},
+		{
+			Test{Name: "foo", OnSave: func() {}, Events: make(chan int)},
+			Test{Name: "foo"},
+			UnsupportedSkip,
+			true,
+		},
+		{
+			Test{Name: "foo", OnSave: func() {}, Events: make(chan int), Hook: func() {}},
+			Test{Name: "foo", OnSave: func() {}, Events: make(chan int), Hook: func() {}},
+			UnsupportedSkip,
+			true,
+		},
+		{
+			[]interface{}{func() {}},
+			[]interface{}{(func())(nil)},
+			UnsupportedSkip,
+			true,
+		},
+		{
+			[]interface{}{func() {}},
+			[]interface{}{(func())(nil)},
+			UnsupportedNilness,
+			false,
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			for _, format := range []Format{FormatMD5, FormatV2} {
+				opts := &HashOptions{Unsupported: tc.Policy}
+				one, err := Hash(tc.One, format, opts)
+				if err != nil {
+					t.Fatalf("Failed to hash %#v: %s", tc.One, err)
+				}
+				two, err := Hash(tc.Two, format, opts)
+				if err != nil {
+					t.Fatalf("Failed to hash %#v: %s", tc.Two, err)
+				}
+
+				if bytes.Equal(one, two) != tc.Match {
+					t.Fatalf("bad, expected: %#v\n\n%#v\n\n%#v", tc.Match, tc.One, tc.Two)
+				}
+			}
+		})
+	}
+
+	// Skipped values take the place of a nil in every format, so they
+	// can't be confused with the values around them
+	for _, format := range []Format{FormatMD5, FormatV2} {
+		opts := &HashOptions{Unsupported: UnsupportedSkip}
+		skipped, err := Hash([]interface{}{func() {}, 1}, format, opts)
+		if err != nil {
+			t.Fatalf("%s: %s", format, err)
+		}
+		withNil, err := Hash([]interface{}{nil, 1}, format, opts)
+		if err != nil {
+			t.Fatalf("%s: %s", format, err)
+		}
+		without, err := Hash([]interface{}{1}, format, opts)
+		if err != nil {
+			t.Fatalf("%s: %s", format, err)
+		}
+
+		if !bytes.Equal(skipped, withNil) {
+			t.Fatalf("%s: skipped func should hash like a nil element", format)
+		}
+		if bytes.Equal(skipped, without) {
+			t.Fatalf("%s: skipped func should not hash like a missing element", format)
+		}
+	}
+
+	// The default is still an error
+	if _, err := Hash(Test{OnSave: func() {}}, FormatV2, nil); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
 type testIncludable struct {
 	Value  string
 	Ignore string
@@ -730,3 +1086,25 @@ func (t *testHashablePointer) Hash() ([]byte, error) {
 
 	return []byte{'z'}, nil
 }
+
+func TestFormat_String(t *testing.T) {
+	for format := FormatMD5; format < formatMax; format++ {
+		parsed, err := ParseFormat(strings.ToUpper(format.String()))
+		if err != nil {
+			t.Fatalf("Failed to parse %s: %s", format, err)
+		}
+		if parsed != format {
+			t.Fatalf("expected %d, got %d", format, parsed)
+		}
+	}
+
+	if s := FormatV2.String(); s != "v2" {
+		t.Fatalf("bad name: %s", s)
+	}
+	if s := formatMax.String(); s != fmt.Sprintf("Format(%d)", formatMax) {
+		t.Fatalf("bad name: %s", s)
+	}
+	if _, err := ParseFormat("crc32"); err == nil {
+		t.Fatal("expected error")
+	}
+}
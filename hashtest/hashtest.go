@@ -0,0 +1,113 @@
+// Package hashtest provides utilities for testing code that hashes values
+// with hashstructure, such as the code generated by
+// cmd/hashstructure-gen.
+package hashtest
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"time"
+)
+
+// maxDepth bounds the nesting of the pointers, slices and maps that Fill
+// creates, so that recursive types are filled in finite time.
+const maxDepth = 4
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Fill sets the value ptr points to to pseudo-random contents derived from
+// seed. The same seed always produces the same contents.
+//
+// Exported struct fields, slices, arrays, maps and pointers are filled
+// recursively, and may be left empty or nil. Unexported fields, interfaces,
+// funcs and channels are left as they are. time.Time values are set to a
+// time in UTC.
+func Fill(ptr any, seed int64) {
+	v := reflect.ValueOf(ptr)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		panic(fmt.Sprintf("hashtest: Fill needs a non-nil pointer, got %T", ptr))
+	}
+
+	f := &filler{r: rand.New(rand.NewSource(seed))}
+	f.fill(v.Elem(), 0)
+}
+
+type filler struct {
+	r *rand.Rand
+}
+
+func (f *filler) fill(v reflect.Value, depth int) {
+	switch v.Kind() {
+	case reflect.Bool:
+		v.SetBool(f.r.Intn(2) == 1)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		// SetInt truncates to the size of the kind
+		v.SetInt(int64(f.r.Uint64()))
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		v.SetUint(f.r.Uint64())
+
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(f.r.NormFloat64() * 1000)
+
+	case reflect.Complex64, reflect.Complex128:
+		v.SetComplex(complex(f.r.NormFloat64(), f.r.NormFloat64()))
+
+	case reflect.String:
+		v.SetString(fmt.Sprintf("s%d", f.r.Intn(1000)))
+
+	case reflect.Struct:
+		if v.Type() == timeType {
+			t := time.Unix(f.r.Int63n(1<<32), f.r.Int63n(1e9)).UTC()
+			v.Set(reflect.ValueOf(t))
+			return
+		}
+
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).PkgPath == "" {
+				f.fill(v.Field(i), depth)
+			}
+		}
+
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			f.fill(v.Index(i), depth)
+		}
+
+	case reflect.Ptr:
+		if depth >= maxDepth || f.r.Intn(4) == 0 {
+			return
+		}
+		p := reflect.New(v.Type().Elem())
+		f.fill(p.Elem(), depth+1)
+		v.Set(p)
+
+	case reflect.Slice:
+		if depth >= maxDepth {
+			return
+		}
+		n := f.r.Intn(4)
+		s := reflect.MakeSlice(v.Type(), n, n)
+		for i := 0; i < n; i++ {
+			f.fill(s.Index(i), depth+1)
+		}
+		v.Set(s)
+
+	case reflect.Map:
+		if depth >= maxDepth {
+			return
+		}
+		n := f.r.Intn(4)
+		m := reflect.MakeMapWithSize(v.Type(), n)
+		for i := 0; i < n; i++ {
+			k := reflect.New(v.Type().Key()).Elem()
+			f.fill(k, depth+1)
+			e := reflect.New(v.Type().Elem()).Elem()
+			f.fill(e, depth+1)
+			m.SetMapIndex(k, e)
+		}
+		v.Set(m)
+	}
+}
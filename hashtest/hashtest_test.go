@@ -0,0 +1,59 @@
+package hashtest
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type fillStruct struct {
+	Bool    bool
+	Int8    int8
+	Uint    uint
+	Float   float64
+	Complex complex64
+	String  string
+	Time    time.Time
+	Array   [2]int
+	Slice   []string
+	Map     map[string]int
+	Ptr     *fillStruct
+	Any     any
+
+	unexported int
+}
+
+func TestFill(t *testing.T) {
+	var a, b fillStruct
+	Fill(&a, 42)
+	Fill(&b, 42)
+	if !reflect.DeepEqual(a, b) {
+		t.Fatalf("same seed, different values:\n%#v\n%#v", a, b)
+	}
+	if reflect.DeepEqual(a, fillStruct{}) {
+		t.Fatal("value was not filled")
+	}
+	if a.Any != nil || a.unexported != 0 {
+		t.Fatalf("interfaces and unexported fields must be left alone: %#v", a)
+	}
+	if a.Time.Location() != time.UTC {
+		t.Fatalf("time is not in UTC: %s", a.Time)
+	}
+
+	// Different seeds fill different values
+	var c fillStruct
+	Fill(&c, 43)
+	if reflect.DeepEqual(a, c) {
+		t.Fatal("different seeds, same values")
+	}
+}
+
+func TestFill_notPointer(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic")
+		}
+	}()
+
+	Fill(fillStruct{}, 1)
+}
@@ -20,3 +20,15 @@ type IncludableMap interface {
 type Hashable interface {
 	Hash() ([]byte, error)
 }
+
+// HashWriter is an interface that can optionally be implemented by a struct
+// to override the hash value, like Hashable. Instead of returning a digest,
+// HashTo writes the canonical bytes of the struct to w, which avoids an
+// intermediate hash and allows hashing nested values with w.WriteValue, or
+// w.WriteField to keep their path in errors. Entries in the struct will not
+// be hashed otherwise.
+//
+// HashWriter takes precedence over Hashable.
+type HashWriter interface {
+	HashTo(w *Writer) error
+}
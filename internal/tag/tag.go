@@ -0,0 +1,103 @@
+// Package tag parses the values of the struct tags that hashstructure
+// reads, such as `hash:"name=id,set,omitempty"`. It is shared by the
+// hashing code and by the hashcheck analyzer, so that both agree on what a
+// tag means.
+package tag
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Tag is a parsed tag value.
+type Tag struct {
+	// Ignore is set by "ignore" and "-"
+	Ignore bool
+
+	Set       bool
+	String    bool
+	Binary    bool
+	Text      bool
+	OmitEmpty bool
+
+	// Name is set by "name=...". On a blank field, it is the name of the
+	// struct type.
+	Name string
+}
+
+// NameOnly reports whether t sets nothing but the name, as is required
+// on blank fields.
+func (t Tag) NameOnly() bool {
+	return t == Tag{Name: t.Name}
+}
+
+// Parse parses the value of a struct tag, a comma-separated list of
+// directives. Invalid values are reported as an error, along with the zero
+// Tag, which hashes the field as usual.
+func Parse(s string) (Tag, error) {
+	var t Tag
+	if s == "" {
+		return t, nil
+	}
+
+	directives := strings.Split(s, ",")
+	for i, d := range directives {
+		key, value, hasValue := strings.Cut(d, "=")
+		for _, prev := range directives[:i] {
+			if prevKey, _, _ := strings.Cut(prev, "="); key == prevKey {
+				return Tag{}, fmt.Errorf("duplicate directive %q", key)
+			}
+		}
+
+		if hasValue {
+			switch key {
+			case "name":
+				if value == "" {
+					return Tag{}, errors.New("empty name")
+				}
+				t.Name = value
+			default:
+				return Tag{}, fmt.Errorf("unknown directive %q", key)
+			}
+			continue
+		}
+
+		switch d {
+		case "ignore", "-":
+			t.Ignore = true
+		case "set":
+			t.Set = true
+		case "string":
+			t.String = true
+		case "binary":
+			t.Binary = true
+		case "text":
+			t.Text = true
+		case "omitempty":
+			t.OmitEmpty = true
+		case "":
+			return Tag{}, errors.New("empty directive")
+		case "name":
+			return Tag{}, fmt.Errorf("%q needs a value, as in name=id", d)
+		default:
+			return Tag{}, fmt.Errorf("unknown directive %q", d)
+		}
+	}
+
+	if t.Ignore && len(directives) > 1 {
+		return Tag{}, fmt.Errorf("%q can't be combined with other directives", "ignore")
+	}
+
+	var encodings int
+	for _, b := range []bool{t.String, t.Binary, t.Text} {
+		if b {
+			encodings++
+		}
+	}
+	if encodings > 1 {
+		return Tag{}, errors.New("only one of string, binary and text can be set")
+	}
+
+	return t, nil
+}
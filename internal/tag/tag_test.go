@@ -0,0 +1,64 @@
+package tag
+
+import (
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		Value string
+		Tag   Tag
+		Err   bool
+	}{
+		{"", Tag{}, false},
+		{"ignore", Tag{Ignore: true}, false},
+		{"-", Tag{Ignore: true}, false},
+		{"set", Tag{Set: true}, false},
+		{"string", Tag{String: true}, false},
+		{"binary", Tag{Binary: true}, false},
+		{"text", Tag{Text: true}, false},
+		{"omitempty", Tag{OmitEmpty: true}, false},
+		{"set,omitempty", Tag{Set: true, OmitEmpty: true}, false},
+		{"omitempty,string", Tag{String: true, OmitEmpty: true}, false},
+		{"text,set", Tag{Text: true, Set: true}, false},
+		{"name=id", Tag{Name: "id"}, false},
+		{"name=id,set,omitempty", Tag{Name: "id", Set: true, OmitEmpty: true}, false},
+		{"string,name=a=b", Tag{Name: "a=b", String: true}, false},
+		{"ignroe", Tag{}, true},
+		{"Set", Tag{}, true},
+		{"set,", Tag{}, true},
+		{",set", Tag{}, true},
+		{"set, omitempty", Tag{}, true},
+		{"set,set", Tag{}, true},
+		{"set,ignroe", Tag{}, true},
+		{"ignore,set", Tag{}, true},
+		{"-,omitempty", Tag{}, true},
+		{"string,binary", Tag{}, true},
+		{"binary,text", Tag{}, true},
+		{"name", Tag{}, true},
+		{"name=", Tag{}, true},
+		{"name=a,name=b", Tag{}, true},
+		{"nmae=id", Tag{}, true},
+		{"set=true", Tag{}, true},
+		{"-,name=id", Tag{}, true},
+	}
+
+	for _, tc := range cases {
+		tag, err := Parse(tc.Value)
+		if (err != nil) != tc.Err {
+			t.Fatalf("%q: unexpected error: %v", tc.Value, err)
+		}
+		if tag != tc.Tag {
+			t.Fatalf("%q: expected %+v, got %+v", tc.Value, tc.Tag, tag)
+		}
+	}
+}
+
+func TestTag_NameOnly(t *testing.T) {
+	if !(Tag{Name: "T"}).NameOnly() || !(Tag{}).NameOnly() {
+		t.Fatal("expected NameOnly")
+	}
+	if (Tag{Name: "T", Set: true}).NameOnly() {
+		t.Fatal("unexpected NameOnly")
+	}
+}
@@ -0,0 +1,93 @@
+package hashstructure
+
+import (
+	"encoding"
+	"reflect"
+)
+
+var (
+	binaryMarshalerType = reflect.TypeOf((*encoding.BinaryMarshaler)(nil)).Elem()
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// visitMarshaler hashes v by its marshalled form if the tag of its field or
+// HashOptions.UseMarshaler asks for it. It returns false if v should be
+// hashed as usual instead.
+func (w *walker) visitMarshaler(v reflect.Value, ctx *visitCtx) (bool, error) {
+	var flags visitFlag
+	if ctx != nil {
+		flags = ctx.Flags & (visitFlagBinary | visitFlagText)
+	}
+	tagged := flags != 0
+
+	if !tagged {
+		// time.Time has always been hashed by MarshalBinary, keep that
+		if !w.opts.UseMarshaler || v.Type() == timeType {
+			return false, nil
+		}
+
+		// Hashable and HashWriter are more specific, let them win
+		if v.Kind() == reflect.Struct {
+			plan := w.hasher.structPlan(v.Type())
+			if plan.hashable || plan.hashWriter ||
+				(v.CanAddr() && (plan.ptrHashable || plan.ptrHashWriter)) {
+				return false, nil
+			}
+		}
+		flags = visitFlagBinary | visitFlagText
+	}
+
+	if flags&visitFlagBinary != 0 {
+		if impl, ok := implements(v, binaryMarshalerType); ok {
+			b, err := impl.(encoding.BinaryMarshaler).MarshalBinary()
+			if err != nil {
+				return true, err
+			}
+			return true, w.writeMarshaled(tagBinary, b)
+		}
+	}
+
+	if flags&visitFlagText != 0 {
+		if impl, ok := implements(v, textMarshalerType); ok {
+			b, err := impl.(encoding.TextMarshaler).MarshalText()
+			if err != nil {
+				return true, err
+			}
+			return true, w.writeMarshaled(tagText, b)
+		}
+	}
+
+	if tagged {
+		// We only show this error if the tag explicitly requests a
+		// marshaler.
+		tag := "binary"
+		if flags == visitFlagText {
+			tag = "text"
+		}
+		return true, &ErrNotMarshaler{Field: ctx.StructField, Tag: tag}
+	}
+
+	return false, nil
+}
+
+// implements returns v, or a pointer to v if it is addressable, as the
+// interface t.
+func implements(v reflect.Value, t reflect.Type) (any, bool) {
+	if v.Type().Implements(t) {
+		return v.Interface(), true
+	}
+	if v.CanAddr() && reflect.PtrTo(v.Type()).Implements(t) {
+		return v.Addr().Interface(), true
+	}
+
+	return nil, false
+}
+
+func (w *walker) writeMarshaled(tag byte, b []byte) error {
+	if err := w.writeHeader(tag, len(b)); err != nil {
+		return err
+	}
+
+	_, err := w.Write(b)
+	return err
+}
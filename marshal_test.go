@@ -0,0 +1,124 @@
+package hashstructure
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+)
+
+// testUUID marshals to text, but hashes the same either way
+type testUUID [16]byte
+
+func (u testUUID) MarshalText() ([]byte, error) {
+	b := make([]byte, hex.EncodedLen(len(u)))
+	hex.Encode(b, u[:])
+	return b, nil
+}
+
+// testDecimal has only private fields, and a canonical binary form where
+// 1.50 and 1.5 are the same
+type testDecimal struct {
+	unscaled int64
+	scale    int
+}
+
+func (d *testDecimal) MarshalBinary() ([]byte, error) {
+	unscaled, scale := d.unscaled, d.scale
+	for scale > 0 && unscaled%10 == 0 {
+		unscaled /= 10
+		scale--
+	}
+	return []byte(strconv.FormatInt(unscaled, 10) + "e-" + strconv.Itoa(scale)), nil
+}
+
+func TestHash_useMarshaler(t *testing.T) {
+	type Test struct {
+		ID     testUUID
+		Amount *testDecimal
+	}
+
+	cases := []struct {
+		One, Two interface{}
+		Match    bool
+	}{
+		{
+			Test{ID: testUUID{1}, Amount: &testDecimal{unscaled: 150, scale: 2}},
+			Test{ID: testUUID{1}, Amount: &testDecimal{unscaled: 15, scale: 1}},
+			true,
+		},
+		{
+			Test{ID: testUUID{1}, Amount: &testDecimal{unscaled: 150, scale: 2}},
+			Test{ID: testUUID{1}, Amount: &testDecimal{unscaled: 15, scale: 2}},
+			false,
+		},
+		{
+			Test{ID: testUUID{1}, Amount: &testDecimal{unscaled: 15, scale: 1}},
+			Test{ID: testUUID{2}, Amount: &testDecimal{unscaled: 15, scale: 1}},
+			false,
+		},
+		{
+			Test{ID: testUUID{1}},
+			Test{ID: testUUID{1}},
+			true,
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			for _, format := range []Format{FormatMD5, FormatV2} {
+				opts := &HashOptions{UseMarshaler: true}
+				one, err := Hash(tc.One, format, opts)
+				if err != nil {
+					t.Fatalf("Failed to hash %#v: %s", tc.One, err)
+				}
+				two, err := Hash(tc.Two, format, opts)
+				if err != nil {
+					t.Fatalf("Failed to hash %#v: %s", tc.Two, err)
+				}
+
+				if bytes.Equal(one, two) != tc.Match {
+					t.Fatalf("bad, expected: %#v\n\n%#v\n\n%#v", tc.Match, tc.One, tc.Two)
+				}
+			}
+		})
+	}
+}
+
+func TestHash_marshalerTags(t *testing.T) {
+	type Test struct {
+		Amount *testDecimal `hash:"binary"`
+		Other  *testDecimal
+	}
+
+	one, err := Hash(Test{Amount: &testDecimal{unscaled: 1}, Other: &testDecimal{unscaled: 1}}, FormatV2, nil)
+	if err != nil {
+		t.Fatalf("Failed to hash: %s", err)
+	}
+	two, err := Hash(Test{Amount: &testDecimal{unscaled: 2}, Other: &testDecimal{unscaled: 2}}, FormatV2, nil)
+	if err != nil {
+		t.Fatalf("Failed to hash: %s", err)
+	}
+	if bytes.Equal(one, two) {
+		t.Fatal("tagged field was not hashed by MarshalBinary")
+	}
+
+	// A nil value is hashed like any other nil
+	if _, err := Hash(Test{}, FormatV2, nil); err != nil {
+		t.Fatalf("Failed to hash: %s", err)
+	}
+
+	type TestBroken struct {
+		Name string `hash:"text"`
+	}
+	_, err = Hash(TestBroken{Name: "foo"}, FormatV2, nil)
+	var enm *ErrNotMarshaler
+	if !errors.As(err, &enm) {
+		t.Fatalf("expected ErrNotMarshaler, got: %v", err)
+	}
+	if enm.Field != "Name" || enm.Tag != "text" {
+		t.Fatalf("bad error: %#v", enm)
+	}
+}
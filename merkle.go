@@ -0,0 +1,143 @@
+package hashstructure
+
+import (
+	"reflect"
+	"sync"
+)
+
+// Cache remembers the hashes of values behind pointers, so that hashing a
+// large value again in Merkle mode only walks the parts that changed. See
+// HashOptions.Cache.
+//
+// Values are looked up by pointer identity alone: changing a value behind
+// a cached pointer does not change its hash until the pointer is passed to
+// Invalidate, or the cache is Reset. The cache keeps every value it holds a
+// hash for from being garbage collected until then.
+//
+// A Cache is safe for concurrent use, but must only be used with a single
+// Format and set of options, as the cached hashes depend on both.
+type Cache struct {
+	mu   sync.Mutex
+	sums map[any]map[visitFlag][]byte
+}
+
+// NewCache returns an empty Cache.
+func NewCache() *Cache {
+	return &Cache{sums: make(map[any]map[visitFlag][]byte)}
+}
+
+// Invalidate forgets the hash of the value that ptr points to. It must be
+// called for every pointer whose value changed, including the pointers
+// leading to it from the value being hashed. ptr must be a pointer, as
+// passed to Hash or held in a field.
+func (c *Cache) Invalidate(ptr any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.sums, ptr)
+}
+
+// Reset forgets all hashes.
+func (c *Cache) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.sums = make(map[any]map[visitFlag][]byte)
+}
+
+// Len returns the number of pointers the cache holds hashes for.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.sums)
+}
+
+func (c *Cache) get(ptr any, f visitFlag) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	sum, ok := c.sums[ptr][f]
+	return sum, ok
+}
+
+func (c *Cache) put(ptr any, f visitFlag, sum []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.sums == nil {
+		c.sums = make(map[any]map[visitFlag][]byte)
+	}
+	if c.sums[ptr] == nil {
+		c.sums[ptr] = make(map[visitFlag][]byte)
+	}
+	c.sums[ptr][f] = sum
+}
+
+// isNode reports whether values of kind k are hashed on their own in
+// Merkle mode.
+func isNode(k reflect.Kind) bool {
+	switch k {
+	case reflect.Ptr, reflect.Struct, reflect.Slice, reflect.Array, reflect.Map:
+		return true
+	}
+
+	return false
+}
+
+// visitNode hashes v on its own and writes the resulting hash, for Merkle
+// mode. v is either a non-nil pointer or a struct, slice, array or map.
+func (w *walker) visitNode(v reflect.Value, ctx *visitCtx) error {
+	var flags visitFlag
+	if ctx != nil {
+		flags = ctx.Flags
+	}
+
+	// Hashes of pointers are cached, unless building a tree, which needs
+	// the whole value to be walked. Maps in structs implementing
+	// IncludableMap depend on the struct, so they aren't cached either.
+	cache := w.opts.Cache
+	if v.Kind() != reflect.Ptr || w.tree || w.opts.Cycles == CycleBackRef {
+		cache = nil
+	} else if ctx != nil {
+		if _, ok := ctx.Struct.(IncludableMap); ok {
+			cache = nil
+		}
+	}
+
+	if cache != nil {
+		if sum, ok := cache.get(v.Interface(), flags); ok {
+			return w.writeNode(sum)
+		}
+	}
+
+	c := w.child()
+	c.inline = true
+	if w.tree {
+		c.tree = true
+		c.nodes = []*Node{{described: true}}
+	}
+	if err := c.visit(v, ctx); err != nil {
+		return err
+	}
+	if w.tree {
+		for _, n := range c.nodes[0].Children {
+			w.attach(n)
+		}
+	}
+
+	sum := c.h.Sum(nil)
+	if cache != nil {
+		cache.put(v.Interface(), flags, sum)
+	}
+	return w.writeNode(sum)
+}
+
+// writeNode writes the hash of a value hashed by visitNode.
+func (w *walker) writeNode(sum []byte) error {
+	if err := w.writeHeader(tagNode, len(sum)); err != nil {
+		return err
+	}
+	_, err := w.Write(sum)
+	return err
+}
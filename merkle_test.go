@@ -0,0 +1,178 @@
+package hashstructure
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestHash_merkle(t *testing.T) {
+	for format := FormatMD5; format < formatMax; format++ {
+		for i, v := range goldenStructs {
+			t.Run(fmt.Sprintf("format_%d/goldenStruct_%d", format, i), func(t *testing.T) {
+				opts := &HashOptions{Merkle: true}
+				h, err := Hash(v, format, opts)
+				if err != nil {
+					t.Fatalf("Failed to hash %#v: %s", v, err)
+				}
+
+				plain, err := Hash(v, format, nil)
+				if err != nil {
+					t.Fatalf("Failed to hash %#v: %s", v, err)
+				}
+				if bytes.Equal(h, plain) {
+					t.Fatal("Merkle hash should differ")
+				}
+
+				root, err := HashTree(v, format, opts)
+				if err != nil {
+					t.Fatalf("Failed to hash %#v: %s", v, err)
+				}
+				if !bytes.Equal(root.Hash, h) {
+					t.Fatalf("non-matching: %d, %d", root.Hash, h)
+				}
+
+				// Caching doesn't change the hash, cold or warm
+				opts.Cache = NewCache()
+				for j := 0; j < 2; j++ {
+					cached, err := Hash(&v, format, opts)
+					if err != nil {
+						t.Fatalf("Failed to hash %#v: %s", v, err)
+					}
+					ptr, err := Hash(&v, format, &HashOptions{Merkle: true})
+					if err != nil {
+						t.Fatalf("Failed to hash %#v: %s", v, err)
+					}
+					if !bytes.Equal(cached, ptr) {
+						t.Fatalf("non-matching: %d, %d", cached, ptr)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestHash_merkleSubtree(t *testing.T) {
+	type Leaf struct {
+		Name string
+	}
+	type Doc struct {
+		Leaf Leaf
+		Ptr  *Leaf
+	}
+
+	// A node is hashed on its own, wherever it is
+	opts := &HashOptions{Merkle: true}
+	leaf := Leaf{Name: "foo"}
+	a, err := Hash(Doc{Leaf: leaf}, FormatV2, opts)
+	if err != nil {
+		t.Fatalf("Failed to hash: %s", err)
+	}
+	b, err := Hash(Doc{Ptr: &leaf}, FormatV2, opts)
+	if err != nil {
+		t.Fatalf("Failed to hash: %s", err)
+	}
+	if bytes.Equal(a, b) {
+		t.Fatal("hashes should differ")
+	}
+
+	paths, err := Diff(Doc{Ptr: &leaf}, Doc{Ptr: &Leaf{Name: "bar"}}, FormatV2, opts)
+	if err != nil {
+		t.Fatalf("Failed to diff: %s", err)
+	}
+	if !reflect.DeepEqual(paths, []string{"Ptr.Name"}) {
+		t.Fatalf("bad paths: %q", paths)
+	}
+}
+
+func TestCache(t *testing.T) {
+	type Section struct {
+		Values map[string]int
+	}
+	type Config struct {
+		Name     string
+		Sections []*Section
+	}
+
+	cfg := &Config{
+		Name: "foo",
+		Sections: []*Section{
+			{Values: map[string]int{"a": 1}},
+			{Values: map[string]int{"b": 2}},
+		},
+	}
+
+	cache := NewCache()
+	opts := &HashOptions{Merkle: true, Cache: cache}
+	hash := func() []byte {
+		t.Helper()
+		h, err := Hash(cfg, FormatV2, opts)
+		if err != nil {
+			t.Fatalf("Failed to hash: %s", err)
+		}
+		return h
+	}
+
+	before := hash()
+	if cache.Len() != 3 {
+		t.Fatalf("expected 3 cached pointers, got %d", cache.Len())
+	}
+
+	// Changes behind cached pointers go unnoticed until invalidated
+	cfg.Sections[1].Values["b"] = 3
+	if !bytes.Equal(hash(), before) {
+		t.Fatal("hash should come from the cache")
+	}
+
+	cache.Invalidate(cfg.Sections[1])
+	if !bytes.Equal(hash(), before) {
+		t.Fatal("hash should come from the cache")
+	}
+
+	cache.Invalidate(cfg)
+	after := hash()
+	if bytes.Equal(after, before) {
+		t.Fatal("hash should change")
+	}
+
+	expected, err := Hash(cfg, FormatV2, &HashOptions{Merkle: true})
+	if err != nil {
+		t.Fatalf("Failed to hash: %s", err)
+	}
+	if !bytes.Equal(after, expected) {
+		t.Fatalf("non-matching: %d, %d", after, expected)
+	}
+
+	cache.Reset()
+	if cache.Len() != 0 {
+		t.Fatalf("expected an empty cache, got %d", cache.Len())
+	}
+}
+
+func TestCache_cycle(t *testing.T) {
+	// With back-references, the hash of a value depends on where it is
+	// found, so the cache must not be used.
+	list := cycleList(1, 2, 3)
+	opts := &HashOptions{Merkle: true, Cycles: CycleBackRef}
+
+	expected, err := Hash(list.Next, FormatV2, opts)
+	if err != nil {
+		t.Fatalf("Failed to hash: %s", err)
+	}
+
+	opts.Cache = NewCache()
+	if _, err := Hash(list, FormatV2, opts); err != nil {
+		t.Fatalf("Failed to hash: %s", err)
+	}
+	h, err := Hash(list.Next, FormatV2, opts)
+	if err != nil {
+		t.Fatalf("Failed to hash: %s", err)
+	}
+	if !bytes.Equal(h, expected) {
+		t.Fatalf("non-matching: %d, %d", h, expected)
+	}
+	if opts.Cache.Len() != 0 {
+		t.Fatalf("expected an empty cache, got %d", opts.Cache.Len())
+	}
+}
@@ -0,0 +1,84 @@
+package hashstructure
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// defaultParallelThreshold is used when HashOptions.ParallelThreshold is 0.
+const defaultParallelThreshold = 256
+
+// forEach calls fn for each i in [0, n). fn must store its results itself,
+// by index.
+//
+// If HashOptions.Parallelism allows and n is large enough, the calls are
+// spread over several goroutines, which is why fn must not modify w. The
+// error returned is always the one of the lowest i that failed, exactly as
+// if fn had been called in order.
+func (w *walker) forEach(n int, fn func(i int) error) error {
+	threshold := w.opts.ParallelThreshold
+	if threshold <= 0 {
+		threshold = defaultParallelThreshold
+	}
+
+	// Trees are built in order, so they are always built sequentially
+	var workers int
+	if w.hasher.workers != nil && n >= threshold && !w.tree {
+	acquire:
+		for workers < n-1 {
+			select {
+			case w.hasher.workers <- struct{}{}:
+				workers++
+			default:
+				break acquire
+			}
+		}
+	}
+
+	if workers == 0 {
+		for i := 0; i < n; i++ {
+			if err := fn(i); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// Indexes are handed out in order, and no more once an error occurred.
+	// So all indexes below a failed one have been handed out, and the
+	// lowest failure is the one a sequential run would have stopped at.
+	var next int64 = -1
+	var failed int32
+	errs := make([]error, n)
+	work := func() {
+		for atomic.LoadInt32(&failed) == 0 {
+			i := int(atomic.AddInt64(&next, 1))
+			if i >= n {
+				return
+			}
+			if err := fn(i); err != nil {
+				errs[i] = err
+				atomic.StoreInt32(&failed, 1)
+			}
+		}
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for j := 0; j < workers; j++ {
+		go func() {
+			defer wg.Done()
+			defer func() { <-w.hasher.workers }()
+			work()
+		}()
+	}
+	work()
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
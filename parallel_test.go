@@ -0,0 +1,122 @@
+package hashstructure
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+type parallelValue struct {
+	Records map[string]benchRecord
+	Set     []benchRecord `hash:"set"`
+	Nested  map[int]map[int]string
+}
+
+func newParallelValue(n int) parallelValue {
+	v := parallelValue{
+		Records: make(map[string]benchRecord, n),
+		Nested:  make(map[int]map[int]string, n),
+	}
+	for i := 0; i < n; i++ {
+		r := benchValue
+		r.Age = i
+		v.Records[fmt.Sprint(i)] = r
+		v.Set = append(v.Set, r)
+
+		v.Nested[i] = make(map[int]string, n)
+		for j := 0; j < n; j++ {
+			v.Nested[i][j] = fmt.Sprint(i * j)
+		}
+	}
+	return v
+}
+
+func TestHash_parallel(t *testing.T) {
+	v := newParallelValue(100)
+	for format := FormatMD5; format < formatMax; format++ {
+		t.Run(fmt.Sprintf("format_%d", format), func(t *testing.T) {
+			expected, err := Hash(v, format, nil)
+			if err != nil {
+				t.Fatalf("Failed to hash: %s", err)
+			}
+
+			h, err := NewHasher(format, &HashOptions{
+				Parallelism:       4,
+				ParallelThreshold: 10,
+			})
+			if err != nil {
+				t.Fatalf("Failed to create hasher: %s", err)
+			}
+
+			// Also hash concurrently, so that the pool is shared
+			var wg sync.WaitGroup
+			for i := 0; i < 4; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					actual, err := h.Hash(v)
+					if err != nil {
+						t.Errorf("Failed to hash: %s", err)
+						return
+					}
+					if !bytes.Equal(actual, expected) {
+						t.Errorf("non-matching: %d, %d", actual, expected)
+					}
+				}()
+			}
+			wg.Wait()
+		})
+	}
+}
+
+func TestHash_parallelError(t *testing.T) {
+	// The error is the one of the first failing element, as when hashing
+	// sequentially
+	v := struct {
+		Set []any `hash:"set"`
+	}{}
+	for i := 0; i < 1000; i++ {
+		if i%100 == 42 {
+			v.Set = append(v.Set, func() {})
+		} else {
+			v.Set = append(v.Set, i)
+		}
+	}
+
+	_, expected := Hash(v, FormatV2, nil)
+	if expected == nil {
+		t.Fatal("expected error")
+	}
+
+	opts := &HashOptions{Parallelism: 8, ParallelThreshold: 1}
+	for i := 0; i < 20; i++ {
+		_, err := Hash(v, FormatV2, opts)
+		if err == nil || err.Error() != expected.Error() {
+			t.Fatalf("expected %q, got %v", expected, err)
+		}
+	}
+}
+
+func BenchmarkHasher_parallelism(b *testing.B) {
+	v := newParallelValue(100)
+	for _, n := range []int{1, 4} {
+		b.Run(fmt.Sprintf("parallelism_%d", n), func(b *testing.B) {
+			h, err := NewHasher(FormatV2, &HashOptions{
+				Parallelism:       n,
+				ParallelThreshold: 10,
+			})
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := h.Hash(v); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
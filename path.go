@@ -0,0 +1,114 @@
+package hashstructure
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// pathElem is a single step from a value to one of its children. Exactly
+// one of the fields is set. They are only formatted when a path is needed,
+// so tracking the path is cheap.
+type pathElem struct {
+	// field is the name of a struct field
+	field string
+
+	// index is the index of an array or slice element, plus one
+	index int
+
+	// key is the key of a map entry
+	key reflect.Value
+}
+
+func fieldElem(name string) pathElem {
+	return pathElem{field: name}
+}
+
+func indexElem(i int) pathElem {
+	return pathElem{index: i + 1}
+}
+
+func keyElem(k reflect.Value) pathElem {
+	return pathElem{key: k}
+}
+
+// formatPath formats a path like Orders[3].Items["sku"].Price.
+func formatPath(path []pathElem) string {
+	var b strings.Builder
+	for _, e := range path {
+		switch {
+		case e.field != "":
+			if b.Len() > 0 {
+				b.WriteByte('.')
+			}
+			b.WriteString(e.field)
+
+		case e.index > 0:
+			fmt.Fprintf(&b, "[%d]", e.index-1)
+
+		case e.key.IsValid():
+			k := e.key
+			for k.Kind() == reflect.Interface && !k.IsNil() {
+				k = k.Elem()
+			}
+			if k.Kind() == reflect.String {
+				fmt.Fprintf(&b, "[%s]", strconv.Quote(k.String()))
+			} else {
+				fmt.Fprintf(&b, "[%v]", k)
+			}
+		}
+	}
+
+	return b.String()
+}
+
+// push appends e to the path of the value being visited. Every push must be
+// followed by a pop.
+func (w *walker) push(e pathElem) {
+	w.path = append(w.path, e)
+	if w.tree {
+		w.openNode()
+	}
+}
+
+func (w *walker) pop() {
+	w.path = w.path[:len(w.path)-1]
+	if w.tree {
+		w.closeNode()
+	}
+}
+
+// pathString returns the path of the value being visited.
+func (w *walker) pathString() string {
+	return formatPath(w.path)
+}
+
+// wrapErr wraps err in a *HashError for the value v being visited, unless
+// it already carries a path.
+func (w *walker) wrapErr(v reflect.Value, err error) error {
+	switch err.(type) {
+	case *HashError, *ErrCycle:
+		return err
+	}
+
+	herr := &HashError{
+		Path: w.pathString(),
+		Kind: v.Kind(),
+		Err:  err,
+	}
+	if v.IsValid() {
+		herr.Type = v.Type()
+	}
+
+	return herr
+}
+
+// fieldError wraps err, which occurred on the named field of the struct
+// being visited.
+func (w *walker) fieldError(name string, v reflect.Value, err error) error {
+	w.push(fieldElem(name))
+	defer w.pop()
+
+	return w.wrapErr(v, err)
+}
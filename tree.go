@@ -0,0 +1,156 @@
+package hashstructure
+
+import (
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Node is a node of the tree returned by HashTree. The tree mirrors the
+// structure of the hashed value: struct fields, slice and array elements
+// and map entries each get a node below the node of their parent.
+type Node struct {
+	// Path is the location of the value, as in HashError.Path. It is empty
+	// for the root.
+	Path string
+
+	// Kind and Type describe the value, after dereferencing pointers and
+	// interfaces. Type is nil if the value is nil.
+	Kind reflect.Kind
+	Type reflect.Type
+
+	// Bytes are the canonical bytes written for the value, including those
+	// of its children. Children that are hashed on their own, such as map
+	// entries and set elements, contribute only their Hash.
+	Bytes []byte
+
+	// Hash is the hash of Bytes. The Hash of the root is the hash of the
+	// value, as returned by Hash.
+	Hash []byte
+
+	Children []*Node
+
+	// described is set once Kind and Type are known
+	described bool
+
+	// unordered is set for sets, whose children are not hashed in order
+	unordered bool
+}
+
+// String returns the tree below n as indented text, one node per line,
+// which is handy when looking for the cause of a changed hash.
+func (n *Node) String() string {
+	var b strings.Builder
+	n.format(&b, 0)
+	return b.String()
+}
+
+func (n *Node) format(b *strings.Builder, depth int) {
+	path := n.Path
+	if path == "" {
+		path = "(root)"
+	}
+
+	typ := "nil"
+	if n.Type != nil {
+		typ = n.Type.String()
+	}
+
+	fmt.Fprintf(b, "%s%s %s %s\n", strings.Repeat("  ", depth), path, typ, hex.EncodeToString(n.Hash))
+	for _, c := range n.Children {
+		c.format(b, depth+1)
+	}
+}
+
+// HashTree hashes v like Hash, and returns a tree of the hashes of all the
+// values within v, to explain how the hash came about. See Node.
+//
+// The tree is built by the same code that computes hashes, so the Hash of
+// the root is always what Hash returns for v. As every node keeps a copy of
+// its bytes, this is meant for debugging rather than everyday use.
+func HashTree(v any, format Format, opts *HashOptions) (*Node, error) {
+	h, err := NewHasher(format, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.HashTree(v)
+}
+
+// HashTree hashes v like Hash, and returns a tree of the hashes of all the
+// values within v. See the package level HashTree function.
+func (h *Hasher) HashTree(v any) (*Node, error) {
+	rv := reflect.ValueOf(v)
+
+	w := h.rootWalker(nil)
+	w.startTree(rv)
+	if err := w.visit(rv, nil); err != nil {
+		return nil, err
+	}
+
+	return w.finishTree(w.h.Sum(nil)), nil
+}
+
+// startTree makes w build a tree, rooted at the value v that w is about to
+// visit.
+func (w *walker) startTree(v reflect.Value) {
+	w.tree = true
+	w.openNode()
+	w.describe(v)
+}
+
+// finishTree returns the root of the tree built by w, given the hash
+// computed by w.
+func (w *walker) finishTree(sum []byte) *Node {
+	root := w.nodes[0]
+	root.Hash = sum
+	w.nodes = nil
+	return root
+}
+
+// openNode starts a node for the value at the current path.
+func (w *walker) openNode() {
+	n := &Node{Path: w.pathString()}
+	w.attach(n)
+	w.nodes = append(w.nodes, n)
+}
+
+// closeNode finishes the innermost node.
+func (w *walker) closeNode() {
+	n := w.nodes[len(w.nodes)-1]
+	h := w.hasher.newHash()
+	h.Write(n.Bytes)
+	n.Hash = h.Sum(nil)
+
+	w.nodes = w.nodes[:len(w.nodes)-1]
+}
+
+// attach adds n as a child of the innermost node.
+func (w *walker) attach(n *Node) {
+	if len(w.nodes) > 0 {
+		parent := w.nodes[len(w.nodes)-1]
+		parent.Children = append(parent.Children, n)
+	}
+}
+
+// describe records the kind and type of v on the innermost node, unless
+// they are known already.
+func (w *walker) describe(v reflect.Value) {
+	n := w.nodes[len(w.nodes)-1]
+	if n.described {
+		return
+	}
+	n.described = true
+
+	for v.Kind() == reflect.Interface || v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	if v.IsValid() {
+		n.Kind = v.Kind()
+		n.Type = v.Type()
+	}
+}
@@ -0,0 +1,101 @@
+package hashstructure
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestHashTree(t *testing.T) {
+	for format := FormatMD5; format < formatMax; format++ {
+		for i, v := range goldenStructs {
+			t.Run(fmt.Sprintf("format_%d/goldenStruct_%d", format, i), func(t *testing.T) {
+				root, err := HashTree(v, format, nil)
+				if err != nil {
+					t.Fatalf("Failed to hash %#v: %s", v, err)
+				}
+
+				h, err := Hash(v, format, nil)
+				if err != nil {
+					t.Fatalf("Failed to hash %#v: %s", v, err)
+				}
+				if !bytes.Equal(root.Hash, h) {
+					t.Fatalf("non-matching: %d, %d", root.Hash, h)
+				}
+			})
+		}
+	}
+}
+
+func TestHashTree_nodes(t *testing.T) {
+	root, err := HashTree(goldenStructA, FormatV2, nil)
+	if err != nil {
+		t.Fatalf("Failed to hash: %s", err)
+	}
+
+	nodes := make(map[string]*Node)
+	var walk func(n *Node)
+	walk = func(n *Node) {
+		nodes[n.Path] = n
+		for _, c := range n.Children {
+			walk(c)
+		}
+	}
+	walk(root)
+
+	for _, path := range []string{
+		"",
+		`AMap`,
+		`AMap["bar"]`,
+		`AMap["bar"].A`,
+		`ASlice`,
+		`ASlice[5]`,
+		`AString`,
+		`APtr`,
+		`APtr.B`,
+	} {
+		if _, ok := nodes[path]; !ok {
+			t.Fatalf("missing node %q in:\n%s", path, root)
+		}
+	}
+
+	// Ignored fields are left out
+	if _, ok := nodes["UUID"]; ok {
+		t.Fatal("ignored field is in the tree")
+	}
+
+	// With FormatV2, a node holds exactly the bytes of its value, so its
+	// hash is the hash of the value on its own.
+	cases := map[string]interface{}{
+		"AString":   goldenStructA.AString,
+		"ASlice":    goldenStructA.ASlice,
+		"ASlice[1]": goldenStructA.ASlice[1],
+		"APtr":      goldenStructA.APtr,
+		"AMap":      goldenStructA.AMap,
+	}
+	for path, v := range cases {
+		h, err := Hash(v, FormatV2, nil)
+		if err != nil {
+			t.Fatalf("Failed to hash %#v: %s", v, err)
+		}
+		if !bytes.Equal(nodes[path].Hash, h) {
+			t.Fatalf("non-matching hash for %s: %d, %d", path, nodes[path].Hash, h)
+		}
+	}
+
+	if nodes["APtr"].Type != nodes["AMap[\"bar\"]"].Type {
+		t.Fatalf("bad types: %s, %s", nodes["APtr"].Type, nodes["AMap[\"bar\"]"].Type)
+	}
+
+	if s := root.String(); !strings.Contains(s, "\n  APtr hashstructure.structB ") {
+		t.Fatalf("bad string:\n%s", s)
+	}
+}
+
+func TestHashTree_error(t *testing.T) {
+	_, err := HashTree(struct{ F func() }{}, FormatV2, nil)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+}
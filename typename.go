@@ -0,0 +1,40 @@
+package hashstructure
+
+import "reflect"
+
+// TypeNamePolicy determines the name of a struct type that is hashed along
+// with the fields of its values.
+type TypeNamePolicy uint
+
+const (
+	// TypeNameShort hashes the name of the type without its package, such
+	// as "Config". Types with the same name in different packages hash
+	// alike, and anonymous structs differently from named ones. This is the
+	// default.
+	TypeNameShort TypeNamePolicy = iota
+
+	// TypeNameNone leaves the name out, so that structs hash alike when
+	// their fields do, whatever their type. Names set with a blank field
+	// are left out as well.
+	TypeNameNone
+
+	// TypeNameQualified hashes the package path along with the name, such
+	// as "example.com/config.Config". Names set with a blank field are
+	// hashed as they are.
+	TypeNameQualified
+)
+
+// typeName returns the name hashed for the struct type t, unless it is set
+// with a blank field.
+func (h *Hasher) typeName(t reflect.Type) string {
+	switch h.opts.TypeNames {
+	case TypeNameNone:
+		return ""
+	case TypeNameQualified:
+		if t.PkgPath() != "" {
+			return t.PkgPath() + "." + t.Name()
+		}
+	}
+
+	return t.Name()
+}
@@ -0,0 +1,106 @@
+package hashstructure
+
+import (
+	"bytes"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// Mutex has the same name as sync.Mutex, and likewise no exported fields
+type Mutex struct{}
+
+type typeNamePoint struct {
+	X, Y int
+}
+
+type typeNamePinned struct {
+	_ struct{} `hash:"name=Point"`
+
+	X, Y int
+}
+
+func TestHash_typeNames(t *testing.T) {
+	anonymous := struct{ X, Y int }{1, 2}
+	named := typeNamePoint{1, 2}
+	pinned := typeNamePinned{X: 1, Y: 2}
+
+	cases := []struct {
+		Policy   TypeNamePolicy
+		One, Two interface{}
+		Match    bool
+	}{
+		{TypeNameShort, Mutex{}, sync.Mutex{}, true},
+		{TypeNameShort, anonymous, named, false},
+		{TypeNameShort, named, pinned, false},
+
+		{TypeNameNone, Mutex{}, sync.Mutex{}, true},
+		{TypeNameNone, anonymous, named, true},
+		{TypeNameNone, named, pinned, true},
+
+		{TypeNameQualified, Mutex{}, sync.Mutex{}, false},
+		{TypeNameQualified, anonymous, named, false},
+		{TypeNameQualified, named, pinned, false},
+		{TypeNameQualified, anonymous, anonymous, true},
+	}
+
+	for _, format := range []Format{FormatMD5, FormatV2} {
+		for i, tc := range cases {
+			opts := &HashOptions{TypeNames: tc.Policy}
+			one, err := Hash(tc.One, format, opts)
+			if err != nil {
+				t.Fatalf("Failed to hash %#v: %s", tc.One, err)
+			}
+			two, err := Hash(tc.Two, format, opts)
+			if err != nil {
+				t.Fatalf("Failed to hash %#v: %s", tc.Two, err)
+			}
+
+			if bytes.Equal(one, two) != tc.Match {
+				t.Fatalf("%s case %d: expected match %v for %T and %T", format, i, tc.Match, tc.One, tc.Two)
+			}
+		}
+	}
+}
+
+func TestHash_typeNamesDefault(t *testing.T) {
+	// TypeNameShort must keep hashes as they were
+	v := typeNamePoint{1, 2}
+	for _, format := range []Format{FormatMD5, FormatV2} {
+		expected, err := Hash(v, format, nil)
+		if err != nil {
+			t.Fatalf("Failed to hash: %s", err)
+		}
+		actual, err := Hash(v, format, &HashOptions{TypeNames: TypeNameShort})
+		if err != nil {
+			t.Fatalf("Failed to hash: %s", err)
+		}
+		if !bytes.Equal(actual, expected) {
+			t.Fatalf("%s: TypeNameShort changed the hash", format)
+		}
+	}
+}
+
+func TestHasher_typeName(t *testing.T) {
+	cases := []struct {
+		Policy TypeNamePolicy
+		Type   reflect.Type
+		Name   string
+	}{
+		{TypeNameShort, reflect.TypeOf(typeNamePoint{}), "typeNamePoint"},
+		{TypeNameNone, reflect.TypeOf(typeNamePoint{}), ""},
+		{TypeNameQualified, reflect.TypeOf(typeNamePoint{}), "go.openly.dev/hashstructure.typeNamePoint"},
+		{TypeNameQualified, reflect.TypeOf(sync.Mutex{}), "sync.Mutex"},
+		{TypeNameQualified, reflect.TypeOf(struct{ X int }{}), ""},
+	}
+
+	for _, tc := range cases {
+		h, err := NewHasher(FormatV2, &HashOptions{TypeNames: tc.Policy})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if name := h.typeName(tc.Type); name != tc.Name {
+			t.Fatalf("expected %q, got %q", tc.Name, name)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package hashstructure
+
+import (
+	"encoding/binary"
+	"fmt"
+	"reflect"
+)
+
+// UnsupportedPolicy determines how values that can't be hashed by content,
+// namely funcs, channels and unsafe pointers, are handled.
+type UnsupportedPolicy uint
+
+const (
+	// UnsupportedError fails the hash. This is the default.
+	UnsupportedError UnsupportedPolicy = iota
+
+	// UnsupportedSkip leaves struct fields holding such values out of the
+	// hash, as if they were tagged with hash:"ignore". Anywhere else, such
+	// as in a slice, the value is hashed as nil.
+	UnsupportedSkip
+
+	// UnsupportedNilness hashes only whether the value is nil.
+	UnsupportedNilness
+)
+
+// unsupported reports whether v, after dereferencing pointers and
+// interfaces, is of a kind that can't be hashed by content.
+func unsupported(v reflect.Value) bool {
+	for v.Kind() == reflect.Interface || v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return false
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return true
+	}
+
+	return false
+}
+
+// visitUnsupported hashes a func, channel or unsafe pointer according to
+// HashOptions.Unsupported.
+func (w *walker) visitUnsupported(v reflect.Value) error {
+	switch w.opts.Unsupported {
+	case UnsupportedSkip:
+		// Hashed as nil, as visit does for invalid values
+		if w.format.canonical() {
+			return w.writeTag(tagNil)
+		}
+		return binary.Write(w, binary.LittleEndian, int64(0))
+
+	case UnsupportedNilness:
+		var tag byte = tagNotNil
+		var tmp int8 = 1
+		if v.IsNil() {
+			tag = tagNil
+			tmp = 0
+		}
+
+		if w.format.canonical() {
+			return w.writeTag(tag)
+		}
+		return binary.Write(w, binary.LittleEndian, tmp)
+	}
+
+	return fmt.Errorf("unknown kind to hash: %s", v.Kind())
+}
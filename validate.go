@@ -0,0 +1,240 @@
+package hashstructure
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"go.openly.dev/hashstructure/internal/tag"
+)
+
+// Validate reports the problems in type t that would make Hash fail for
+// values of t with the given options, so that they can be found when a
+// program starts rather than when the first unusual value is hashed. It
+// looks for:
+//
+//   - funcs, channels and unsafe pointers, unless opts.Unsupported allows
+//     them
+//   - fields tagged "string" that don't implement fmt.Stringer
+//   - fields tagged "binary" or "text" that don't implement the marshaler
+//   - tag values that can't be parsed, which Hash ignores, and blank
+//     fields that set more than the type name
+//   - fields hashed under the same name, because of the name directive
+//
+// The error is a *ValidationError listing every problem, or nil. Problems
+// in a struct, array, slice or map type used in several places are only
+// reported at the first.
+//
+// Validate only knows about types, so it can't look into interfaces, and
+// it assumes that methods on pointer receivers are used, as they are for
+// values behind pointers.
+func Validate(t reflect.Type, opts *HashOptions) error {
+	h, err := NewHasher(FormatV2, opts)
+	if err != nil {
+		return err
+	}
+
+	return h.Validate(t)
+}
+
+// Validate reports the problems in type t that would make h fail to hash
+// values of t. See the package level Validate function.
+func (h *Hasher) Validate(t reflect.Type) error {
+	if t == nil {
+		return errors.New("hashstructure: Validate of nil type")
+	}
+
+	v := &validator{hasher: h, seen: make(map[reflect.Type]bool)}
+	v.typ(t, "")
+	if len(v.errs) > 0 {
+		return &ValidationError{Errors: v.errs}
+	}
+	return nil
+}
+
+type validator struct {
+	hasher *Hasher
+
+	// seen holds the arrays, slices, maps and structs validated already.
+	// Each is only validated once, at the first path it is found at, which
+	// also ends recursion.
+	seen map[reflect.Type]bool
+
+	errs []*HashError
+}
+
+func (v *validator) report(path string, t reflect.Type, err error) {
+	v.errs = append(v.errs, &HashError{
+		Path: path,
+		Kind: t.Kind(),
+		Type: t,
+		Err:  err,
+	})
+}
+
+// elem returns the type hashed for a value of type t, after pointers, or
+// nil if that isn't known from the type.
+func (v *validator) elem(t reflect.Type) reflect.Type {
+	for {
+		if _, ok := v.hasher.opts.TypeHashers[t]; ok {
+			return nil
+		}
+		switch t.Kind() {
+		case reflect.Interface:
+			return nil
+		case reflect.Ptr:
+			t = t.Elem()
+		default:
+			return t
+		}
+	}
+}
+
+// typ validates t, found at path. Elements of arrays, slices and maps are
+// written as [] in paths.
+func (v *validator) typ(t reflect.Type, path string) {
+	opts := &v.hasher.opts
+
+	t = v.elem(t)
+	if t == nil {
+		return
+	}
+	switch t.Kind() {
+	case reflect.Array, reflect.Slice, reflect.Map, reflect.Struct:
+		if v.seen[t] {
+			return
+		}
+		v.seen[t] = true
+	}
+
+	if t == timeType {
+		return
+	}
+	if opts.UseMarshaler && implementsType(t, binaryMarshalerType, textMarshalerType) {
+		return
+	}
+
+	switch k := t.Kind(); k {
+	case reflect.Array, reflect.Slice:
+		v.typ(t.Elem(), path+"[]")
+
+	case reflect.Map:
+		v.typ(t.Key(), path+"[]")
+		v.typ(t.Elem(), path+"[]")
+
+	case reflect.Struct:
+		v.structType(t, path)
+
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		if opts.Unsupported == UnsupportedError {
+			v.report(path, t, fmt.Errorf("unknown kind to hash: %s", k))
+		}
+	}
+}
+
+func (v *validator) structType(t reflect.Type, path string) {
+	plan := v.hasher.structPlan(t)
+	if plan.optional || plan.hashable || plan.hashWriter || plan.ptrHashable || plan.ptrHashWriter {
+		// The fields are never looked at
+		return
+	}
+
+	var named bool
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		blank := field.Name == "_"
+		if field.PkgPath != "" && !blank {
+			continue
+		}
+
+		value := field.Tag.Get(v.hasher.tag)
+		tag, err := tag.Parse(value)
+		if err == nil && blank && value != "" {
+			switch {
+			case !tag.NameOnly():
+				err = errors.New("only name can be set on a blank field")
+			case named:
+				err = errors.New("type name is already set by another blank field")
+			}
+			named = true
+		}
+		if err != nil {
+			v.report(fieldPath(path, field.Name), field.Type, &ErrTag{
+				Field: field.Name,
+				Tag:   value,
+				Err:   err,
+			})
+		}
+	}
+
+	// The name directive can make fields share a name
+	hashed := make(map[string]string, len(plan.fields))
+	for _, field := range plan.fields {
+		name := field.nameValue.String()
+		if prev, ok := hashed[name]; ok {
+			sf := t.Field(field.index)
+			v.report(fieldPath(path, field.name), sf.Type, &ErrTag{
+				Field: field.name,
+				Tag:   sf.Tag.Get(v.hasher.tag),
+				Err:   fmt.Errorf("name %q is also hashed for %s", name, prev),
+			})
+			continue
+		}
+		hashed[name] = field.name
+	}
+
+	opts := &v.hasher.opts
+	for _, field := range plan.fields {
+		ft := t.Field(field.index).Type
+		fpath := fieldPath(path, field.name)
+		if opts.Unsupported == UnsupportedSkip && unsupportedType(ft) {
+			continue
+		}
+
+		if field.str || opts.UseStringer {
+			if field.stringer || (field.str && field.iface) {
+				// Hashed as a string, or depends on the value
+				continue
+			}
+			if field.str {
+				v.report(fpath, ft, &ErrNotStringer{Field: field.name})
+				continue
+			}
+		}
+
+		if field.binary || field.text {
+			iface, name := binaryMarshalerType, "binary"
+			if field.text {
+				iface, name = textMarshalerType, "text"
+			}
+
+			elem := v.elem(ft)
+			if elem == nil || implementsType(elem, iface) {
+				continue
+			}
+			v.report(fpath, ft, &ErrNotMarshaler{Field: field.name, Tag: name})
+			continue
+		}
+
+		v.typ(ft, fpath)
+	}
+}
+
+func fieldPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// implementsType reports whether t or a pointer to t implements any of
+// the interfaces.
+func implementsType(t reflect.Type, ifaces ...reflect.Type) bool {
+	ptr := reflect.PtrTo(t)
+	for _, iface := range ifaces {
+		if t.Implements(iface) || ptr.Implements(iface) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,233 @@
+package hashstructure
+
+import (
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+type validateStringer struct{ A int }
+
+func (validateStringer) String() string { return "s" }
+
+type validateHashable struct{ F func() }
+
+func (validateHashable) Hash() ([]byte, error) { return []byte{1}, nil }
+
+type validateList struct {
+	Next *validateList
+	F    func()
+}
+
+func TestValidate(t *testing.T) {
+	type paths []string
+
+	cases := []struct {
+		Name  string
+		Type  reflect.Type
+		Opts  *HashOptions
+		Paths paths
+	}{
+		{
+			Name: "valid",
+			Type: reflect.TypeOf(struct {
+				Name    string
+				Tags    []string `hash:"set"`
+				Meta    map[string]any
+				When    time.Time
+				Str     validateStringer `hash:"string"`
+				Any     any              `hash:"string"`
+				IP      net.IP           `hash:"text"`
+				Ignored func()           `hash:"ignore"`
+				Hash    validateHashable
+				private chan int
+			}{}),
+		},
+		{
+			Name: "unsupported",
+			Type: reflect.TypeOf(struct {
+				F     func()
+				Chans []chan int
+				Map   map[string]*unsafe.Pointer
+				Inner struct{ F func() }
+			}{}),
+			Paths: paths{"F", "Chans[]", "Map[]", "Inner.F"},
+		},
+		{
+			Name: "unsupported skip",
+			Type: reflect.TypeOf(struct {
+				F     func()
+				Chans []chan int
+			}{}),
+			Opts: &HashOptions{Unsupported: UnsupportedSkip},
+		},
+		{
+			Name: "unsupported nilness",
+			Type: reflect.TypeOf(struct{ F func() }{}),
+			Opts: &HashOptions{Unsupported: UnsupportedNilness},
+		},
+		{
+			Name: "tags",
+			Type: reflect.TypeOf(struct {
+				Count int           `hash:"string"`
+				Data  []byte        `hash:"binary"`
+				Dur   time.Duration `hash:"text"`
+				Typo  string        `hash:"ignroe"`
+				Both  time.Time     `hash:"binary,text"`
+			}{}),
+			Paths: paths{"Typo", "Both", "Count", "Data", "Dur"},
+		},
+		{
+			Name: "tag name",
+			Type: reflect.TypeOf(struct {
+				Typo string `other:"sett"`
+			}{}),
+			Opts:  &HashOptions{TagName: "other"},
+			Paths: paths{"Typo"},
+		},
+		{
+			Name: "blank",
+			Type: reflect.TypeOf(struct {
+				_ struct{} `hash:"name=T"`
+				_ struct{} `hash:"name=U"`
+				_ int      `hash:"set"`
+				_ int      `hash:"name=V,omitempty"`
+				_ int      `hash:"nmae=V"`
+				A string   `hash:"name=a"`
+			}{}),
+			Paths: paths{"_", "_", "_", "_"},
+		},
+		{
+			Name: "names",
+			Type: reflect.TypeOf(struct {
+				ID     string
+				Key    string `hash:"name=ID"`
+				Old    string `hash:"ignore"`
+				Legacy string `hash:"name=Old"`
+				A, B   int    `hash:"name=AB"`
+				Inner  struct {
+					X int
+					Y int `hash:"name=X"`
+				}
+			}{}),
+			Paths: paths{"Key", "B", "Inner.Y"},
+		},
+		{
+			Name:  "recursive",
+			Type:  reflect.TypeOf(validateList{}),
+			Paths: paths{"F"},
+		},
+		{
+			Name:  "pointer",
+			Type:  reflect.TypeOf(&[]func(){}),
+			Paths: paths{"[]"},
+		},
+		{
+			Name: "type hashers",
+			Type: reflect.TypeOf(struct{ F func() }{}),
+			Opts: &HashOptions{TypeHashers: map[reflect.Type]TypeHashFunc{
+				reflect.TypeOf(func() {}): func(w *Writer, v any) error { return nil },
+			}},
+		},
+		{
+			Name: "stringer option",
+			Type: reflect.TypeOf(struct{ S validateStringer }{}),
+			Opts: &HashOptions{UseStringer: true},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			err := Validate(tc.Type, tc.Opts)
+			if len(tc.Paths) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				return
+			}
+
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("expected *ValidationError, got: %v", err)
+			}
+			var got paths
+			for _, e := range verr.Errors {
+				got = append(got, e.Path)
+			}
+			if !reflect.DeepEqual(got, tc.Paths) {
+				t.Fatalf("got paths %q, want %q\n%s", got, tc.Paths, err)
+			}
+		})
+	}
+}
+
+func TestValidate_names(t *testing.T) {
+	type T struct {
+		ID  string
+		Key string `hash:"name=ID"`
+	}
+
+	err := Validate(reflect.TypeOf(T{}), nil)
+	want := `hashstructure: Key has invalid tag value "name=ID": name "ID" is also hashed for ID (at Key)`
+	if err == nil || err.Error() != want {
+		t.Fatalf("got %v, want %q", err, want)
+	}
+}
+
+func TestValidate_errors(t *testing.T) {
+	type T struct {
+		Count int    `hash:"string"`
+		Typo  string `hash:"ignroe"`
+	}
+
+	err := Validate(reflect.TypeOf(T{}), nil)
+	var verr *ValidationError
+	if !errors.As(err, &verr) || len(verr.Errors) != 2 {
+		t.Fatalf("expected two errors, got: %v", err)
+	}
+
+	var et *ErrTag
+	if !errors.As(verr.Errors[0], &et) || et.Tag != "ignroe" {
+		t.Fatalf("expected ErrTag, got: %v", verr.Errors[0])
+	}
+	var ens *ErrNotStringer
+	if !errors.As(verr.Errors[1], &ens) || ens.Field != "Count" {
+		t.Fatalf("expected ErrNotStringer, got: %v", verr.Errors[1])
+	}
+
+	want := `hashstructure: Typo has invalid tag value "ignroe": unknown directive "ignroe" (at Typo); ` +
+		`hashstructure: Count has hash:"string" set, but does not implement fmt.Stringer (at Count)`
+	if err.Error() != want {
+		t.Fatalf("got %q, want %q", err, want)
+	}
+
+	if err := Validate(nil, nil); err == nil {
+		t.Fatal("expected error for nil type")
+	}
+}
+
+// Validate must agree with Hash on the values it can see.
+func TestValidate_hash(t *testing.T) {
+	values := []any{
+		struct{ F func() }{},
+		struct {
+			Count int `hash:"string"`
+		}{},
+		&struct {
+			Data []byte `hash:"binary"`
+		}{},
+		struct{ S validateStringer }{},
+		&validateList{Next: &validateList{}},
+	}
+
+	for _, v := range values {
+		hashErr := func() error { _, err := Hash(v, FormatV2, nil); return err }()
+		validErr := Validate(reflect.TypeOf(v), nil)
+		if (hashErr == nil) != (validErr == nil) {
+			t.Fatalf("%T: Hash returned %v, Validate %v", v, hashErr, validErr)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package hashstructure
+
+import (
+	"reflect"
+)
+
+// TypeHashFunc writes the canonical bytes of v, a value of the type it is
+// registered for in HashOptions.TypeHashers, to w. Values that are equal
+// must produce the same bytes.
+type TypeHashFunc func(w *Writer, v any) error
+
+// Writer is where custom hash functions write the canonical bytes of a
+// value. The bytes are fed into the hash being computed.
+type Writer struct {
+	w *walker
+}
+
+// Write implements io.Writer.
+func (w *Writer) Write(p []byte) (int, error) {
+	return w.w.Write(p)
+}
+
+// WriteValue hashes v, exactly as it would be hashed as part of any other
+// value, and writes it to w. It must not be passed the value that is
+// currently writing itself, as that recurses forever.
+func (w *Writer) WriteValue(v any) error {
+	return w.w.visit(reflect.ValueOf(v), nil)
+}
+
+// WriteField hashes v like WriteValue, as the named field of the value
+// being written. The name isn't hashed, but errors within v and the tree
+// built by HashTree carry the path to v through the field.
+func (w *Writer) WriteField(name string, v any) error {
+	w.w.push(fieldElem(name))
+	defer w.w.pop()
+
+	return w.w.visit(reflect.ValueOf(v), nil)
+}
+
+// Format returns the format of the hash being computed.
+func (w *Writer) Format() Format {
+	return w.w.format
+}
+
+// Options returns the options of the hash being computed.
+func (w *Writer) Options() HashOptions {
+	return *w.w.opts
+}
+
+// visitCustom hashes a value by calling fn. With the canonical encoding,
+// whatever fn writes is hashed separately and the digest is written in its
+// place, so that it is self-delimiting like any other value.
+func (w *walker) visitCustom(fn func(*Writer) error) error {
+	if !w.format.canonical() {
+		return fn(&Writer{w: w})
+	}
+
+	c := w.child()
+	if w.tree {
+		c.startTree(reflect.Value{})
+	}
+	if err := fn(&Writer{w: c}); err != nil {
+		return err
+	}
+
+	sum := c.h.Sum(nil)
+	if w.tree {
+		// The nodes written by fn go below the node of the value, as they
+		// do with the other formats, where fn writes to w itself.
+		n := w.nodes[len(w.nodes)-1]
+		n.Children = append(n.Children, c.finishTree(sum).Children...)
+	}
+	if err := w.writeHeader(tagCustom, len(sum)); err != nil {
+		return err
+	}
+	_, err := w.Write(sum)
+	return err
+}
@@ -0,0 +1,292 @@
+package hashstructure
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/netip"
+	"reflect"
+	"testing"
+)
+
+func TestHash_typeHashers(t *testing.T) {
+	type Test struct {
+		Amount *big.Int
+		Addr   netip.Addr
+	}
+
+	opts := &HashOptions{
+		TypeHashers: map[reflect.Type]TypeHashFunc{
+			reflect.TypeOf((*big.Int)(nil)): func(w *Writer, v any) error {
+				_, err := fmt.Fprint(w, v.(*big.Int).String())
+				return err
+			},
+			reflect.TypeOf(netip.Addr{}): func(w *Writer, v any) error {
+				b, err := v.(netip.Addr).MarshalBinary()
+				if err != nil {
+					return err
+				}
+				_, err = w.Write(b)
+				return err
+			},
+		},
+	}
+
+	cases := []struct {
+		One, Two interface{}
+		Match    bool
+	}{
+		{
+			Test{Amount: big.NewInt(42), Addr: netip.MustParseAddr("10.0.0.1")},
+			Test{Amount: big.NewInt(42), Addr: netip.MustParseAddr("10.0.0.1")},
+			true,
+		},
+		{
+			Test{Amount: big.NewInt(42), Addr: netip.MustParseAddr("10.0.0.1")},
+			Test{Amount: big.NewInt(43), Addr: netip.MustParseAddr("10.0.0.1")},
+			false,
+		},
+		{
+			Test{Amount: big.NewInt(42), Addr: netip.MustParseAddr("10.0.0.1")},
+			Test{Amount: big.NewInt(42), Addr: netip.MustParseAddr("10.0.0.2")},
+			false,
+		},
+		{
+			Test{Addr: netip.MustParseAddr("10.0.0.1")},
+			Test{Amount: big.NewInt(0), Addr: netip.MustParseAddr("10.0.0.1")},
+			false,
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			for _, format := range []Format{FormatMD5, FormatV2} {
+				one, err := Hash(tc.One, format, opts)
+				if err != nil {
+					t.Fatalf("Failed to hash %#v: %s", tc.One, err)
+				}
+				two, err := Hash(tc.Two, format, opts)
+				if err != nil {
+					t.Fatalf("Failed to hash %#v: %s", tc.Two, err)
+				}
+
+				if bytes.Equal(one, two) != tc.Match {
+					t.Fatalf("bad, expected: %#v\n\n%#v\n\n%#v", tc.Match, tc.One, tc.Two)
+				}
+			}
+		})
+	}
+}
+
+type testShape interface {
+	Area() float64
+}
+
+type testSquare struct {
+	Side  float64
+	Color string
+}
+
+func (s testSquare) Area() float64 { return s.Side * s.Side }
+
+func TestHash_typeHashersInterface(t *testing.T) {
+	type Test struct {
+		Shape  testShape
+		Shapes []testShape
+	}
+
+	var calls int
+	opts := &HashOptions{
+		TypeHashers: map[reflect.Type]TypeHashFunc{
+			reflect.TypeOf((*testShape)(nil)).Elem(): func(w *Writer, v any) error {
+				calls++
+				return w.WriteValue(v.(testShape).Area())
+			},
+		},
+	}
+
+	// Shapes are hashed by their area only
+	one := Test{Shape: testSquare{2, "red"}, Shapes: []testShape{testSquare{1, "red"}}}
+	two := Test{Shape: testSquare{2, "blue"}, Shapes: []testShape{testSquare{1, "blue"}}}
+	three := Test{Shape: testSquare{3, "red"}, Shapes: []testShape{testSquare{1, "red"}}}
+
+	for _, format := range []Format{FormatMD5, FormatV2} {
+		calls = 0
+		hashOne, err := Hash(one, format, opts)
+		if err != nil {
+			t.Fatalf("Failed to hash: %s", err)
+		}
+		if calls != 2 {
+			t.Fatalf("%s: expected 2 calls, got %d", format, calls)
+		}
+		hashTwo, err := Hash(two, format, opts)
+		if err != nil {
+			t.Fatalf("Failed to hash: %s", err)
+		}
+		hashThree, err := Hash(three, format, opts)
+		if err != nil {
+			t.Fatalf("Failed to hash: %s", err)
+		}
+
+		if !bytes.Equal(hashOne, hashTwo) {
+			t.Fatalf("%s: expected match", format)
+		}
+		if bytes.Equal(hashOne, hashThree) {
+			t.Fatalf("%s: expected no match", format)
+		}
+	}
+
+	// Nil interfaces are hashed as usual
+	calls = 0
+	if _, err := Hash(Test{}, FormatV2, opts); err != nil {
+		t.Fatalf("Failed to hash: %s", err)
+	}
+	if calls != 0 {
+		t.Fatalf("expected no calls for nil, got %d", calls)
+	}
+}
+
+func TestHash_typeHashersError(t *testing.T) {
+	type Test struct {
+		Amount *big.Int
+	}
+
+	opts := &HashOptions{
+		TypeHashers: map[reflect.Type]TypeHashFunc{
+			reflect.TypeOf((*big.Int)(nil)): func(w *Writer, v any) error {
+				return errors.New("oh no")
+			},
+		},
+	}
+
+	_, err := Hash(Test{Amount: big.NewInt(1)}, FormatV2, opts)
+	var herr *HashError
+	if !errors.As(err, &herr) {
+		t.Fatalf("expected HashError, got: %v", err)
+	}
+	if herr.Path != "Amount" || herr.Err.Error() != "oh no" {
+		t.Fatalf("bad error: %#v", herr)
+	}
+}
+
+type testHashWriter struct {
+	ID    string
+	Name  string
+	Inner *testHashWriterInner
+}
+
+// HashTo hashes everything but the ID
+func (t testHashWriter) HashTo(w *Writer) error {
+	if _, err := fmt.Fprint(w, t.Name); err != nil {
+		return err
+	}
+	return w.WriteField("Inner", t.Inner)
+}
+
+type testHashWriterInner struct {
+	Values []int
+	Hook   interface{}
+}
+
+type testHashWriterPointer struct {
+	Value string
+}
+
+func (t *testHashWriterPointer) HashTo(w *Writer) error {
+	_, err := fmt.Fprint(w, t.Value)
+	return err
+}
+
+func TestHash_hashWriter(t *testing.T) {
+	cases := []struct {
+		One, Two interface{}
+		Match    bool
+	}{
+		{
+			testHashWriter{ID: "1", Name: "foo", Inner: &testHashWriterInner{Values: []int{1, 2}}},
+			testHashWriter{ID: "2", Name: "foo", Inner: &testHashWriterInner{Values: []int{1, 2}}},
+			true,
+		},
+		{
+			testHashWriter{Name: "foo", Inner: &testHashWriterInner{Values: []int{1, 2}}},
+			testHashWriter{Name: "foo", Inner: &testHashWriterInner{Values: []int{2, 1}}},
+			false,
+		},
+		{
+			testHashWriter{Name: "foo"},
+			testHashWriter{Name: "bar"},
+			false,
+		},
+		{
+			&testHashWriterPointer{Value: "foo"},
+			&testHashWriterPointer{Value: "foo"},
+			true,
+		},
+		{
+			&testHashWriterPointer{Value: "foo"},
+			&testHashWriterPointer{Value: "bar"},
+			false,
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			for _, format := range []Format{FormatMD5, FormatV2} {
+				one, err := Hash(tc.One, format, nil)
+				if err != nil {
+					t.Fatalf("Failed to hash %#v: %s", tc.One, err)
+				}
+				two, err := Hash(tc.Two, format, nil)
+				if err != nil {
+					t.Fatalf("Failed to hash %#v: %s", tc.Two, err)
+				}
+
+				if bytes.Equal(one, two) != tc.Match {
+					t.Fatalf("bad, expected: %#v\n\n%#v\n\n%#v", tc.Match, tc.One, tc.Two)
+				}
+			}
+		})
+	}
+
+	// Errors from nested values keep their path
+	v := testHashWriter{Inner: &testHashWriterInner{Hook: func() {}}}
+	for _, format := range []Format{FormatMD5, FormatV2} {
+		_, err := Hash(struct{ W testHashWriter }{v}, format, nil)
+		var herr *HashError
+		if !errors.As(err, &herr) {
+			t.Fatalf("expected HashError, got: %v", err)
+		}
+		if herr.Path != "W.Inner.Hook" {
+			t.Fatalf("%s: bad path: %s", format, herr.Path)
+		}
+	}
+}
+
+func TestHashTree_hashWriter(t *testing.T) {
+	v := struct{ W testHashWriter }{
+		testHashWriter{Name: "foo", Inner: &testHashWriterInner{Values: []int{1, 2}}},
+	}
+	for _, format := range []Format{FormatMD5, FormatV2} {
+		root, err := HashTree(v, format, nil)
+		if err != nil {
+			t.Fatalf("%s: Failed to hash: %s", format, err)
+		}
+
+		h, err := Hash(v, format, nil)
+		if err != nil {
+			t.Fatalf("%s: Failed to hash: %s", format, err)
+		}
+		if !bytes.Equal(root.Hash, h) {
+			t.Fatalf("%s: non-matching: %d, %d", format, root.Hash, h)
+		}
+
+		if len(root.Children) != 1 || root.Children[0].Path != "W" {
+			t.Fatalf("%s: expected a node for W in:\n%s", format, root)
+		}
+		w := root.Children[0]
+		if len(w.Children) != 1 || w.Children[0].Path != "W.Inner" {
+			t.Fatalf("%s: expected a node for W.Inner in:\n%s", format, root)
+		}
+	}
+}